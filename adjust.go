@@ -0,0 +1,202 @@
+package controlifx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ColorDelta is a relative change of a color, such as 10% brighter or the hue shifted by 30°.
+type ColorDelta struct {
+	// Hue is added to the hue in degrees, wrapping around the hue circle.
+	Hue float64
+	// Saturation and Brightness are added to the saturation and brightness in the range [-1, 1].
+	Saturation float64
+	Brightness float64
+	// Kelvin is added to the color temperature.
+	Kelvin int
+}
+
+// Apply returns the color changed by the delta. The saturation and brightness are clamped to their range and the
+// color temperature to the range [MinKelvin, MaxKelvin].
+func (o ColorDelta) Apply(c HSBK) HSBK {
+	c.Hue += quantizeHue(o.Hue)
+
+	c.Saturation = addFraction(c.Saturation, o.Saturation)
+	c.Brightness = addFraction(c.Brightness, o.Brightness)
+
+	if o.Kelvin != 0 {
+		k := int(c.Kelvin) + o.Kelvin
+		if k < MinKelvin {
+			k = MinKelvin
+		} else if k > MaxKelvin {
+			k = MaxKelvin
+		}
+
+		c.Kelvin = uint16(k)
+	}
+
+	return c
+}
+
+func addFraction(v uint16, delta float64) uint16 {
+	if delta == 0 {
+		return v
+	}
+
+	return quantize(float64(v)/0xffff + delta)
+}
+
+// AdjustColor changes the color of the devices by the delta over the duration. The states of all devices are read
+// at once, then each device is sent its changed color and the acknowledgement is awaited. Devices whose color
+// wouldn't change aren't sent anything. AdjustColor returns the devices that were changed, and an error listing the
+// devices that couldn't be.
+//
+// Deltas that only change the brightness are sent to all devices at once as LightSetDimAbsolute, which leaves the
+// rest of the color alone even if it changed since it was read. LightSetDimRelative would save reading the states,
+// but resending it when an acknowledgement is lost would dim a device twice, while resending an absolute brightness
+// is harmless. Devices that don't acknowledge the dimming, such as those whose firmware doesn't support it, are sent
+// their changed color instead.
+func (o Connection) AdjustColor(ctx context.Context, devices []Device, delta ColorDelta, duration time.Duration) (changed []Device, err error) {
+	recMsgs, err := o.SendToAndGetContext(ctx, LightGet(), devices, TypeFilter(LightStateType))
+	if err != nil {
+		return
+	}
+
+	var errs []string
+	colors := make(map[MAC]HSBK, len(devices))
+	brightnesses := make(map[Device]uint16)
+
+	for _, d := range devices {
+		recMsg, ok := recMsgs[d]
+		if !ok {
+			errs = append(errs, d.String()+": "+ErrNoResponse.Error())
+			continue
+		}
+
+		current := recMsg.Payload.(*LightStateLanMessage).Color
+		c := delta.Apply(current)
+		if c == current {
+			continue
+		}
+
+		colors[d.Mac] = c
+		if delta.Hue == 0 && delta.Saturation == 0 && delta.Kelvin == 0 {
+			brightnesses[d] = c.Brightness
+		}
+	}
+
+	dimmed := o.dim(ctx, brightnesses, duration)
+
+	for _, d := range devices {
+		c, ok := colors[d.Mac]
+		if !ok {
+			continue
+		}
+
+		if !dimmed[d.Mac] {
+			if err := NewLight(o, d).SetColor(ctx, c, duration); err != nil {
+				errs = append(errs, d.String()+": "+err.Error())
+				continue
+			}
+		}
+
+		changed = append(changed, d)
+	}
+
+	if len(errs) > 0 {
+		err = fmt.Errorf("cannot adjust color: %s", strings.Join(errs, "; "))
+	}
+
+	return
+}
+
+// SetBrightness sets the brightness of the devices over the duration, leaving the rest of their colors alone. The
+// devices are sent LightSetDimAbsolute at once, and the states of those that don't acknowledge it are read and they
+// are sent their color with the brightness instead, as AdjustColor does. SetBrightness returns the devices that were
+// changed, and an error listing the devices that couldn't be.
+func (o Connection) SetBrightness(ctx context.Context, devices []Device, brightness uint16, duration time.Duration) (changed []Device, err error) {
+	brightnesses := make(map[Device]uint16, len(devices))
+	for _, d := range devices {
+		brightnesses[d] = brightness
+	}
+
+	dimmed := o.dim(ctx, brightnesses, duration)
+
+	var rest []Device
+	for _, d := range devices {
+		if !dimmed[d.Mac] {
+			rest = append(rest, d)
+		}
+	}
+
+	var (
+		errs    []string
+		recMsgs map[Device]ReceivableLanMessage
+	)
+	if len(rest) > 0 {
+		if recMsgs, err = o.SendToAndGetContext(ctx, LightGet(), rest, TypeFilter(LightStateType)); err != nil {
+			return
+		}
+	}
+
+	for _, d := range devices {
+		if dimmed[d.Mac] {
+			changed = append(changed, d)
+			continue
+		}
+
+		recMsg, ok := recMsgs[d]
+		if !ok {
+			errs = append(errs, d.String()+": "+ErrNoResponse.Error())
+			continue
+		}
+
+		c := recMsg.Payload.(*LightStateLanMessage).Color
+		c.Brightness = brightness
+
+		if err := NewLight(o, d).SetColor(ctx, c, duration); err != nil {
+			errs = append(errs, d.String()+": "+err.Error())
+			continue
+		}
+
+		changed = append(changed, d)
+	}
+
+	if len(errs) > 0 {
+		err = fmt.Errorf("cannot set brightness: %s", strings.Join(errs, "; "))
+	}
+
+	return
+}
+
+// dim sends LightSetDimAbsolute with its brightness to each device at once and returns the devices that
+// acknowledged it. Those that didn't, even because of an error, are left to be sent their color instead.
+func (o Connection) dim(ctx context.Context, brightnesses map[Device]uint16, duration time.Duration) map[MAC]bool {
+	dimmed := make(map[MAC]bool)
+	if len(brightnesses) == 0 {
+		return dimmed
+	}
+
+	devices := make([]Device, 0, len(brightnesses))
+	for d := range brightnesses {
+		devices = append(devices, d)
+	}
+
+	recMsgs, _ := o.sendEachAndGet(ctx, func(d Device) SendableLanMessage {
+		msg := LightSetDimAbsolute(LightSetDimAbsoluteLanMessage{
+			Brightness: brightnesses[d],
+			Duration:   DurationToMs(duration),
+		})
+		msg.Header.FrameAddress.AckRequired = true
+
+		return msg
+	}, devices, TypeFilter(AcknowledgementType))
+
+	for d := range recMsgs {
+		dimmed[d.Mac] = true
+	}
+
+	return dimmed
+}
@@ -0,0 +1,145 @@
+package controlifx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestColorDelta_Apply(t *testing.T) {
+	c := HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0xf000, Kelvin: 3500}
+
+	tests := []struct {
+		delta    ColorDelta
+		expected HSBK
+	}{
+		{ColorDelta{}, c},
+		{ColorDelta{Brightness: 0.1}, HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0xffff, Kelvin: 3500}},
+		{ColorDelta{Brightness: -1}, HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0, Kelvin: 3500}},
+		{ColorDelta{Saturation: 0.25}, HSBK{Hue: 0x1000, Saturation: 0xc000, Brightness: 0xf000, Kelvin: 3500}},
+		{ColorDelta{Hue: 90}, HSBK{Hue: 0x5000, Saturation: 0x8000, Brightness: 0xf000, Kelvin: 3500}},
+		// Wraps around the hue circle.
+		{ColorDelta{Hue: -45}, HSBK{Hue: 0xf000, Saturation: 0x8000, Brightness: 0xf000, Kelvin: 3500}},
+		{ColorDelta{Hue: 720}, c},
+		{ColorDelta{Kelvin: 10000}, HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0xf000, Kelvin: MaxKelvin}},
+		{ColorDelta{Kelvin: -3000}, HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0xf000, Kelvin: MinKelvin}},
+	}
+
+	for _, test := range tests {
+		if v := test.delta.Apply(c); v != test.expected {
+			t.Errorf("%+v: expected '%+v', got '%+v'", test.delta, test.expected, v)
+		}
+	}
+}
+
+func TestConnection_AdjustColor(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	a, da := testLight(t, 1)
+	defer a.Close()
+	b, db := testLight(t, 2)
+	defer b.Close()
+	e, de := testLight(t, 3)
+	defer e.Close()
+
+	c := fakelight.HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0x8000, Kelvin: 3500}
+	for _, f := range []*fakelight.Light{a, b, e} {
+		f.Update(func(s *fakelight.State) { s.Color = c })
+	}
+	e.Update(func(s *fakelight.State) { s.Color.Brightness = 0x4000 })
+
+	// The firmware of b and e doesn't support dimming, so they're sent their colors instead.
+	b.Drop(LightSetDimAbsoluteType, -1)
+	e.Drop(LightSetDimAbsoluteType, -1)
+
+	ctx := context.Background()
+	devices := []Device{da, db, de}
+
+	start := time.Now()
+	changed, err := conn.AdjustColor(ctx, devices, ColorDelta{Brightness: 0.25}, time.Second)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(changed) != 3 {
+		t.Errorf("expected '%v', got '%v'", devices, changed)
+	}
+
+	// Both brightnesses are dimmed at once, so the retries are only waited through once.
+	if elapsed, retries := time.Since(start), (DefaultRetries+1)*NormalTimeout*time.Millisecond; elapsed > retries*3/2 {
+		t.Errorf("expected at most '%v', got '%v'", retries*3/2, elapsed)
+	}
+
+	expected := c
+	expected.Brightness = 0xc000
+	for _, f := range []*fakelight.Light{a, b} {
+		if v := f.State().Color; v != expected {
+			t.Errorf("%d: expected '%v', got '%v'", f.Mac, expected, v)
+		}
+	}
+	expected.Brightness = 0x8000
+	if v := e.State().Color; v != expected {
+		t.Errorf("%d: expected '%v', got '%v'", e.Mac, expected, v)
+	}
+
+	if n := a.Count(LightSetColorType); n != 0 {
+		t.Errorf("expected '0' colors set on a dimmed device, got '%d'", n)
+	}
+	if n := b.Count(LightSetColorType); n != 1 {
+		t.Errorf("expected '1' color set on a device that can't dim, got '%d'", n)
+	}
+
+	// Other deltas are always sent as colors.
+	if _, err := conn.AdjustColor(ctx, []Device{da}, ColorDelta{Hue: 90, Brightness: 0.1}, 0); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if n := a.Count(LightSetDimAbsoluteType); n != 1 {
+		t.Errorf("expected '1' dim, got '%d'", n)
+	}
+	if n := a.Count(LightSetColorType); n != 1 {
+		t.Errorf("expected '1' color set, got '%d'", n)
+	}
+}
+
+func TestConnection_SetBrightness(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	a, da := testLight(t, 1)
+	defer a.Close()
+	b, db := testLight(t, 2)
+	defer b.Close()
+
+	c := fakelight.HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0x8000, Kelvin: 3500}
+	for _, f := range []*fakelight.Light{a, b} {
+		f.Update(func(s *fakelight.State) { s.Color = c })
+	}
+	b.Drop(LightSetDimAbsoluteType, -1)
+
+	devices := []Device{da, db}
+	changed, err := conn.SetBrightness(context.Background(), devices, 0x2000, 0)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(changed) != 2 {
+		t.Errorf("expected '%v', got '%v'", devices, changed)
+	}
+
+	expected := c
+	expected.Brightness = 0x2000
+	for _, f := range []*fakelight.Light{a, b} {
+		if v := f.State().Color; v != expected {
+			t.Errorf("%d: expected '%v', got '%v'", f.Mac, expected, v)
+		}
+	}
+
+	// Only b, which can't dim, is read and sent its color.
+	if n := a.Count(LightGetType) + a.Count(LightSetColorType); n != 0 {
+		t.Errorf("expected '0' reads and colors, got '%d'", n)
+	}
+	if n := b.Count(LightSetColorType); n != 1 {
+		t.Errorf("expected '1' color set, got '%d'", n)
+	}
+}
@@ -0,0 +1,203 @@
+// Package autobright adjusts the brightness of LIFX lights to the ambient light measured by the sensor of a LIFX
+// device, dimming them as the room gets brighter.
+package autobright
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yath/controlifx"
+)
+
+const (
+	// DefaultInterval is the interval between updates of controllers whose options don't specify one.
+	DefaultInterval = 30 * time.Second
+
+	// DefaultTransition is the duration of the brightness transitions of controllers whose options don't specify
+	// one.
+	DefaultTransition = 5 * time.Second
+
+	// DefaultHysteresis is the hysteresis of controllers whose options don't specify one.
+	DefaultHysteresis = 0.25
+)
+
+type (
+	// Options configure a controller.
+	Options struct {
+		// Curve maps the illuminance to the brightness. It's DefaultCurve if nil.
+		Curve Curve
+		// Hysteresis is how much the illuminance must change, as a fraction of the illuminance at the last
+		// adjustment, before the brightness is adjusted again, so that lights don't follow every passing cloud or
+		// flicker between two brightnesses. It's DefaultHysteresis if 0, and every change counts if it's negative.
+		Hysteresis float64
+
+		// Interval is the interval between updates.
+		Interval time.Duration
+		// Transition is the duration of the brightness transitions.
+		Transition time.Duration
+	}
+
+	// Status is the state of a controller as of its last update.
+	Status struct {
+		// Lux is the last illuminance read from the sensor.
+		Lux float64
+		// LastRead is the time Lux was read.
+		LastRead time.Time
+		// Brightness is the brightness last set, for the illuminance at LastApplied.
+		Brightness float64
+		// LastApplied is the time the brightness was last adjusted.
+		LastApplied time.Time
+		// Err is the error of the last update, if it failed.
+		Err error
+	}
+
+	// Controller periodically reads the illuminance from a sensor and sets the brightness of lights from a curve.
+	// The sensor shouldn't be lit by the lights, or the lights react to their own brightness; the hysteresis only
+	// damps that.
+	Controller struct {
+		conn   controlifx.Connection
+		sensor controlifx.Device
+		opts   Options
+
+		mu      sync.Mutex
+		devices map[controlifx.MAC]controlifx.Device
+		status  Status
+		// lux is the illuminance the brightness was last adjusted for, and applied is set while it's valid.
+		lux     float64
+		applied bool
+	}
+)
+
+// New returns a controller reading the sensor of the device, without lights. The curve must be valid.
+func New(conn controlifx.Connection, sensor controlifx.Device, opts Options) (*Controller, error) {
+	if opts.Curve == nil {
+		opts.Curve = DefaultCurve
+	}
+	if opts.Hysteresis < 0 {
+		opts.Hysteresis = 0
+	} else if opts.Hysteresis == 0 {
+		opts.Hysteresis = DefaultHysteresis
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Transition <= 0 {
+		opts.Transition = DefaultTransition
+	}
+
+	if err := opts.Curve.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid curve: %v", err)
+	}
+
+	return &Controller{
+		conn:    conn,
+		sensor:  sensor,
+		opts:    opts,
+		devices: make(map[controlifx.MAC]controlifx.Device),
+	}, nil
+}
+
+// Add starts controlling the device. Its brightness is adjusted at the next update.
+func (o *Controller) Add(device controlifx.Device) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.devices[device.Mac] = device
+	o.applied = false
+}
+
+// Remove stops controlling the device.
+func (o *Controller) Remove(mac controlifx.MAC) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.devices, mac)
+}
+
+// Status returns the status of the controller.
+func (o *Controller) Status() Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.status
+}
+
+// Run updates the lights every Interval until the context is done, and returns the context's error.
+func (o *Controller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Errors are recorded in the status; the lights are updated again next time.
+		o.Update(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update reads the illuminance from the sensor and, if it moved out of the hysteresis since the last adjustment,
+// sets the lights to the brightness of the curve, keeping their hue, saturation and color temperature. Lights that
+// are off are adjusted too, so that they come on at the right brightness.
+func (o *Controller) Update(ctx context.Context) error {
+	err := o.update(ctx)
+
+	o.mu.Lock()
+	o.status.Err = err
+	o.mu.Unlock()
+
+	return err
+}
+
+func (o *Controller) update(ctx context.Context) error {
+	lux, err := controlifx.NewLight(o.conn, o.sensor).AmbientLight(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot read sensor %s: %w", o.sensor, err)
+	}
+
+	o.mu.Lock()
+	o.status.Lux, o.status.LastRead = float64(lux), time.Now()
+	adjust := !o.applied || changed(float64(lux), o.lux, o.opts.Hysteresis)
+	devices := make([]controlifx.Device, 0, len(o.devices))
+	for _, d := range o.devices {
+		devices = append(devices, d)
+	}
+	o.mu.Unlock()
+
+	if !adjust || len(devices) == 0 {
+		return nil
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].Mac < devices[j].Mac
+	})
+
+	brightness := o.opts.Curve.Brightness(float64(lux))
+
+	// Only the brightness is sent where the lights support it, so that changes of their colors by others in the
+	// meantime aren't undone. The lights are adjusted again at the next update if this fails.
+	if _, err := o.conn.SetBrightness(ctx, devices, uint16(math.Round(brightness*0xffff)), o.opts.Transition); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	o.lux, o.applied = float64(lux), true
+	o.status.Brightness, o.status.LastApplied = brightness, time.Now()
+	o.mu.Unlock()
+
+	return nil
+}
+
+// changed returns whether the illuminance moved out of the hysteresis around the illuminance of the last adjustment.
+// The hysteresis is relative on the logarithmic lux scale of the curves, so that it's meaningful in the dark as well
+// as in daylight.
+func changed(lux, last, hysteresis float64) bool {
+	return math.Abs(logLux(lux)-logLux(last)) > math.Log1p(hysteresis)
+}
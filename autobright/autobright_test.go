@@ -0,0 +1,197 @@
+package autobright
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"testing"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestCurveValidate(t *testing.T) {
+	tests := []struct {
+		curve Curve
+		valid bool
+	}{
+		{DefaultCurve, true},
+		{Curve{{0, 0.5}}, true},
+		{nil, false},
+		{Curve{{-1, 0.5}}, false},
+		{Curve{{10, 1.5}}, false},
+		{Curve{{10, 1}, {10, 0.5}}, false},
+		{Curve{{100, 1}, {10, 0.5}}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.curve.Validate(); (err == nil) != test.valid {
+			t.Errorf("%v: expected valid '%v', got error '%v'", test.curve, test.valid, err)
+		}
+	}
+}
+
+func TestCurveBrightness(t *testing.T) {
+	c := Curve{{9, 1}, {99, 0.5}, {999, 0}}
+
+	tests := []struct {
+		lux, expected float64
+	}{
+		{0, 1},
+		{9, 1},
+		// Halfway on the logarithmic scale.
+		{math.Sqrt(10*100) - 1, 0.75},
+		{99, 0.5},
+		{math.Sqrt(100*1000) - 1, 0.25},
+		{999, 0},
+		{5000, 0},
+	}
+
+	for _, test := range tests {
+		if v := c.Brightness(test.lux); math.Abs(v-test.expected) > 1e-9 {
+			t.Errorf("%v lux: expected '%v', got '%v'", test.lux, test.expected, v)
+		}
+	}
+}
+
+func TestChanged(t *testing.T) {
+	tests := []struct {
+		lux, last, hysteresis float64
+		expected              bool
+	}{
+		{99, 99, 0.25, false},
+		{119, 99, 0.25, false},
+		{129, 99, 0.25, true},
+		{84, 99, 0.25, false},
+		{69, 99, 0.25, true},
+		{0.2, 0, 0.25, false},
+		{1, 0, 0.25, true},
+		{100, 99, 0, true},
+	}
+
+	for _, test := range tests {
+		if v := changed(test.lux, test.last, test.hysteresis); v != test.expected {
+			t.Errorf("%v lux after %v lux with hysteresis %v: expected '%v', got '%v'", test.lux, test.last,
+				test.hysteresis, test.expected, v)
+		}
+	}
+}
+
+// testLight returns a fake light with the color and its Device. It must be closed.
+func testLight(t *testing.T, mac uint64, color fakelight.HSBK) (*fakelight.Light, controlifx.Device) {
+	f, err := fakelight.New(mac)
+	if err != nil {
+		t.Fatal("cannot start fake device:", err)
+	}
+
+	f.Update(func(s *fakelight.State) { s.Color = color })
+
+	return f, controlifx.Device{Addr: f.Addr(), Mac: controlifx.MAC(mac)}
+}
+
+func TestController_Update(t *testing.T) {
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	conn, err := controlifx.ConnectFrom(loopback, loopback)
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+	defer conn.Close()
+
+	red := fakelight.HSBK{Saturation: 0xffff, Brightness: 0xffff, Kelvin: 3500}
+
+	sensor, ds := testLight(t, 1, fakelight.HSBK{})
+	defer sensor.Close()
+	a, da := testLight(t, 2, red)
+	defer a.Close()
+	b, db := testLight(t, 3, red)
+	defer b.Close()
+
+	// b's firmware doesn't support dimming, so it's sent its color instead.
+	b.Drop(controlifx.LightSetDimAbsoluteType, -1)
+
+	o, err := New(conn, ds, Options{Curve: Curve{{9, 1}, {99, 0.5}}})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	o.Add(da)
+	o.Add(db)
+
+	ctx := context.Background()
+
+	sensor.Update(func(s *fakelight.State) { s.Lux = 99 })
+	if err := o.Update(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	half := red
+	half.Brightness = 0x8000
+	for _, f := range []*fakelight.Light{a, b} {
+		if v := f.State().Color; v != half {
+			t.Errorf("%x: expected '%v', got '%v'", f.Mac, half, v)
+		}
+	}
+	if n := a.Count(controlifx.LightSetColorType); n != 0 {
+		t.Errorf("expected '0' colors set on a dimmed light, got '%d'", n)
+	}
+	if s := o.Status(); s.Lux != 99 || s.Brightness != 0.5 || s.LastApplied.IsZero() {
+		t.Errorf("expected 99 lux and brightness 0.5, got '%+v'", s)
+	}
+
+	// Someone changes the color of a; dimming keeps the change.
+	blue := fakelight.HSBK{Hue: 0xaaaa, Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500}
+	a.Update(func(s *fakelight.State) { s.Color = blue })
+
+	sensor.Update(func(s *fakelight.State) { s.Lux = 9 })
+	if err := o.Update(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	blue.Brightness = 0xffff
+	if v := a.State().Color; v != blue {
+		t.Errorf("expected '%v', got '%v'", blue, v)
+	}
+
+	// Within the hysteresis nothing is sent.
+	n := a.Count(controlifx.LightSetDimAbsoluteType)
+	sensor.Update(func(s *fakelight.State) { s.Lux = 10 })
+	if err := o.Update(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if v := a.Count(controlifx.LightSetDimAbsoluteType); v != n {
+		t.Errorf("expected '%d' dims, got '%d'", n, v)
+	}
+}
+
+func TestController_Update_sensor(t *testing.T) {
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	conn, err := controlifx.ConnectFrom(loopback, loopback)
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+	defer conn.Close()
+
+	sensor, ds := testLight(t, 1, fakelight.HSBK{})
+	defer sensor.Close()
+	a, da := testLight(t, 2, fakelight.HSBK{Brightness: 0xffff, Kelvin: 3500})
+	defer a.Close()
+
+	o, err := New(conn, ds, Options{})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	o.Add(da)
+
+	sensor.Drop(controlifx.SensorGetAmbientLightType, -1)
+
+	err = o.Update(context.Background())
+	if !errors.Is(err, controlifx.ErrNoResponse) {
+		t.Errorf("expected '%v', got '%v'", controlifx.ErrNoResponse, err)
+	}
+	if s := o.Status(); s.Err != err || !s.LastRead.IsZero() {
+		t.Errorf("expected the error and no read, got '%+v'", s)
+	}
+	if n := len(a.Received()); n != 0 {
+		t.Errorf("expected '0' messages to the light, got '%d'", n)
+	}
+}
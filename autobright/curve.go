@@ -0,0 +1,76 @@
+package autobright
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Point is the brightness of the lights at an illuminance.
+type Point struct {
+	Lux float64
+	// Brightness is in the range [0, 1].
+	Brightness float64
+}
+
+// Curve maps the illuminance to the brightness of the lights. Between points the brightness is interpolated linearly
+// on a logarithmic lux scale, which follows how bright a room appears. Below the first and above the last point the
+// brightness is that of the point.
+type Curve []Point
+
+// DefaultCurve lights a dark room fully and dims the lights as daylight comes in.
+var DefaultCurve = Curve{
+	{5, 1},
+	{50, 0.8},
+	{300, 0.4},
+	{1000, 0.1},
+}
+
+// Validate checks that the curve has points, that they are in order of illuminance, and that their values are in
+// range.
+func (o Curve) Validate() error {
+	if len(o) == 0 {
+		return errors.New("curve has no points")
+	}
+
+	for i, p := range o {
+		if p.Lux < 0 {
+			return fmt.Errorf("point %d at %v lux is negative", i, p.Lux)
+		}
+		if p.Brightness < 0 || p.Brightness > 1 {
+			return fmt.Errorf("point %d at %v lux: brightness %v is out of range [0, 1]", i, p.Lux, p.Brightness)
+		}
+		if i > 0 && p.Lux <= o[i-1].Lux {
+			return fmt.Errorf("point %d at %v lux is not above point %d at %v lux", i, p.Lux, i-1, o[i-1].Lux)
+		}
+	}
+
+	return nil
+}
+
+// Brightness returns the brightness of the curve at the illuminance.
+func (o Curve) Brightness(lux float64) float64 {
+	if len(o) == 0 {
+		return 0
+	}
+
+	if lux <= o[0].Lux {
+		return o[0].Brightness
+	}
+
+	for i := 1; i < len(o); i++ {
+		if lux < o[i].Lux {
+			prev, next := o[i-1], o[i]
+			t := (logLux(lux) - logLux(prev.Lux)) / (logLux(next.Lux) - logLux(prev.Lux))
+
+			return prev.Brightness + (next.Brightness-prev.Brightness)*t
+		}
+	}
+
+	return o[len(o)-1].Brightness
+}
+
+// logLux returns the illuminance on a logarithmic scale that is defined at 0 lux.
+func logLux(lux float64) float64 {
+	return math.Log1p(lux)
+}
@@ -0,0 +1,249 @@
+// Package circadian makes LIFX lights follow the day: warm and dim in the morning and at night, cool and bright
+// around noon.
+package circadian
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/effects"
+)
+
+// DefaultInterval is the interval between updates of controllers whose options don't specify one.
+const DefaultInterval = time.Minute
+
+type (
+	// Options configure a controller.
+	Options struct {
+		// Kelvin is the color temperature over the day. It's DefaultKelvin if nil.
+		Kelvin Curve
+		// Brightness is the brightness in the range [0, 1] over the day. It's DefaultBrightness if nil.
+		Brightness Curve
+
+		// Interval is the interval between updates.
+		Interval time.Duration
+		// Transition is the duration of the transition to each update, or Interval if 0, so that the lights change
+		// continuously.
+		Transition time.Duration
+	}
+
+	// Status is the state of a device as last seen by the controller.
+	Status struct {
+		Device controlifx.Device
+		// Overridden is set when the color of the light was changed by someone else. The light is left alone until
+		// it's turned off or Resume is called.
+		Overridden bool
+		// Target is the color last sent to the light.
+		Target controlifx.HSBK
+		// LastApplied is the time Target was sent.
+		LastApplied time.Time
+		// Err is the error of the last update, if it failed.
+		Err error
+
+		// from is the color of the light when Target was sent.
+		from controlifx.HSBK
+	}
+
+	// Controller periodically sets the color temperature and brightness of lights from curves over the day.
+	Controller struct {
+		conn controlifx.Connection
+		opts Options
+
+		mu      sync.Mutex
+		devices map[controlifx.MAC]*Status
+	}
+)
+
+// New returns a controller without devices. The curves must be valid.
+func New(conn controlifx.Connection, opts Options) (*Controller, error) {
+	if opts.Kelvin == nil {
+		opts.Kelvin = DefaultKelvin
+	}
+	if opts.Brightness == nil {
+		opts.Brightness = DefaultBrightness
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Transition <= 0 {
+		opts.Transition = opts.Interval
+	}
+
+	if err := opts.Kelvin.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kelvin curve: %v", err)
+	}
+	if err := opts.Brightness.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid brightness curve: %v", err)
+	}
+
+	for _, p := range opts.Kelvin {
+		if p.Value < controlifx.MinKelvin || p.Value > controlifx.MaxKelvin {
+			return nil, fmt.Errorf("invalid kelvin curve: %v at %v is out of range [%d, %d]", p.Value, p.At,
+				controlifx.MinKelvin, controlifx.MaxKelvin)
+		}
+	}
+	for _, p := range opts.Brightness {
+		if p.Value < 0 || p.Value > 1 {
+			return nil, fmt.Errorf("invalid brightness curve: %v at %v is out of range [0, 1]", p.Value, p.At)
+		}
+	}
+
+	return &Controller{
+		conn:    conn,
+		opts:    opts,
+		devices: make(map[controlifx.MAC]*Status),
+	}, nil
+}
+
+// Add starts controlling the device.
+func (o *Controller) Add(device controlifx.Device) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if s, ok := o.devices[device.Mac]; ok {
+		s.Device = device
+		return
+	}
+
+	o.devices[device.Mac] = &Status{Device: device}
+}
+
+// Remove stops controlling the device.
+func (o *Controller) Remove(mac controlifx.MAC) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.devices, mac)
+}
+
+// Resume takes back control of an overridden device at the next update.
+func (o *Controller) Resume(mac controlifx.MAC) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if s, ok := o.devices[mac]; ok {
+		s.resume()
+	}
+}
+
+// Status returns the status of all devices, ordered by MAC address.
+func (o *Controller) Status() []Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	statuses := make([]Status, 0, len(o.devices))
+	for _, s := range o.devices {
+		statuses = append(statuses, *s)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Device.Mac < statuses[j].Device.Mac
+	})
+
+	return statuses
+}
+
+// Target returns the color the curves give for the time. Its hue and saturation are zero.
+func (o *Controller) Target(t time.Time) controlifx.HSBK {
+	return controlifx.HSBK{
+		Brightness: uint16(math.Round(o.opts.Brightness.At(t) * 0xffff)),
+		Kelvin:     uint16(math.Round(o.opts.Kelvin.At(t))),
+	}
+}
+
+// Run updates the devices every Interval until the context is done, and returns the context's error.
+func (o *Controller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Errors are recorded in the status; devices are updated again next time.
+		o.Update(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update reads the state of the devices and sends those that are on and not overridden the target color for the
+// time, transitioning over Transition.
+func (o *Controller) Update(ctx context.Context, t time.Time) error {
+	o.mu.Lock()
+	devices := make([]controlifx.Device, 0, len(o.devices))
+	for _, s := range o.devices {
+		devices = append(devices, s.Device)
+	}
+	o.mu.Unlock()
+
+	if len(devices) == 0 {
+		return nil
+	}
+
+	states, err := effects.Snapshot(ctx, o.conn, devices)
+	if err != nil {
+		return err
+	}
+
+	target := o.Target(t)
+
+	for _, d := range devices {
+		state, ok := states[d.Mac]
+		if !ok {
+			continue
+		}
+
+		o.mu.Lock()
+		s, tracked := o.devices[d.Mac]
+		update := tracked && s.observe(state)
+		o.mu.Unlock()
+
+		if !update {
+			continue
+		}
+
+		// Keep the hue so that the light only changes its white.
+		c := target
+		c.Hue = state.Color.Hue
+
+		err := controlifx.NewLight(o.conn, d).SetColor(ctx, c, o.opts.Transition)
+
+		o.mu.Lock()
+		if s, tracked := o.devices[d.Mac]; tracked {
+			s.Err = err
+			if err == nil {
+				s.from, s.Target, s.LastApplied = state.Color, c, t
+			}
+		}
+		o.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (o *Status) resume() {
+	o.Overridden = false
+	o.LastApplied = time.Time{}
+}
+
+// observe updates the status with the state of the light and returns whether the light should be updated. A light
+// whose color isn't between the color it had when it was last updated and the color it was sent has been overridden.
+func (o *Status) observe(state controlifx.LightStateLanMessage) bool {
+	if state.Power == controlifx.OffPowerLevel {
+		o.resume()
+		return false
+	}
+
+	if !o.Overridden && !o.LastApplied.IsZero() {
+		o.Overridden = !state.Color.Between(o.from, o.Target)
+	}
+
+	return !o.Overridden
+}
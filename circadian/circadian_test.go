@@ -0,0 +1,96 @@
+package circadian
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+)
+
+func TestCurveValue(t *testing.T) {
+	c := Curve{
+		{6 * time.Hour, 2000},
+		{12 * time.Hour, 5000},
+		{18 * time.Hour, 3000},
+	}
+
+	tests := []struct {
+		at       time.Duration
+		expected float64
+	}{
+		{6 * time.Hour, 2000},
+		{9 * time.Hour, 3500},
+		{12 * time.Hour, 5000},
+		{15 * time.Hour, 4000},
+		{18 * time.Hour, 3000},
+		// Wraps around midnight from 18:00 at 3000 to 06:00 at 2000.
+		{0, 2500},
+		{21 * time.Hour, 2750},
+		{3 * time.Hour, 2250},
+	}
+
+	for _, test := range tests {
+		if v := c.Value(test.at); v != test.expected {
+			t.Errorf("Value(%v): expected %v, got %v", test.at, test.expected, v)
+		}
+	}
+
+	noon := time.Date(2020, 6, 1, 12, 0, 0, 0, time.Local)
+	if v := c.At(noon); v != 5000 {
+		t.Errorf("At(%v): expected 5000, got %v", noon, v)
+	}
+}
+
+func TestCurveValidate(t *testing.T) {
+	for _, c := range []Curve{
+		nil,
+		{{25 * time.Hour, 1}},
+		{{2 * time.Hour, 1}, {time.Hour, 1}},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%v): expected an error", c)
+		}
+	}
+
+	if err := DefaultKelvin.Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := DefaultBrightness.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	on := func(c controlifx.HSBK) controlifx.LightStateLanMessage {
+		return controlifx.LightStateLanMessage{Color: c, Power: controlifx.OnPowerLevel}
+	}
+
+	s := &Status{
+		from:        controlifx.HSBK{Brightness: 30000, Kelvin: 3000},
+		Target:      controlifx.HSBK{Brightness: 40000, Kelvin: 3500},
+		LastApplied: time.Now(),
+	}
+
+	if !s.observe(on(controlifx.HSBK{Brightness: 35000, Kelvin: 3250})) {
+		t.Error("light in transition: expected an update")
+	}
+
+	if s.observe(on(controlifx.HSBK{Hue: 100, Saturation: 0xffff, Brightness: 35000, Kelvin: 3250})) {
+		t.Error("light set to a color: expected no update")
+	}
+	if !s.Overridden {
+		t.Error("light set to a color: expected overridden")
+	}
+
+	if s.observe(on(controlifx.HSBK{Brightness: 35000, Kelvin: 3250})) {
+		t.Error("overridden light: expected no update")
+	}
+
+	if s.observe(controlifx.LightStateLanMessage{}) || s.Overridden {
+		t.Error("light turned off: expected no update and the override cleared")
+	}
+
+	if !s.observe(on(controlifx.HSBK{Brightness: 0xffff, Kelvin: 6500})) {
+		t.Error("light turned on again: expected an update")
+	}
+}
@@ -0,0 +1,97 @@
+package circadian
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// Point is the value of a curve at a time of day.
+type Point struct {
+	// At is the time since midnight.
+	At    time.Duration
+	Value float64
+}
+
+// Curve is a value that changes over the day. Between points the value is interpolated linearly, and after the last
+// point it moves toward the first point of the next day.
+type Curve []Point
+
+var (
+	// DefaultKelvin is warm in the morning and at night, and cool around noon.
+	DefaultKelvin = Curve{
+		{6 * time.Hour, 2700},
+		{9 * time.Hour, 4000},
+		{12 * time.Hour, 5500},
+		{16 * time.Hour, 4500},
+		{19 * time.Hour, 3000},
+		{22 * time.Hour, 2200},
+	}
+
+	// DefaultBrightness is bright during the day and dim at night.
+	DefaultBrightness = Curve{
+		{6 * time.Hour, 0.4},
+		{9 * time.Hour, 0.9},
+		{12 * time.Hour, 1},
+		{18 * time.Hour, 0.8},
+		{21 * time.Hour, 0.4},
+		{23 * time.Hour, 0.1},
+	}
+)
+
+// Validate checks that the curve has points, that they are within a day, and that they are in order.
+func (o Curve) Validate() error {
+	if len(o) == 0 {
+		return errors.New("curve has no points")
+	}
+
+	for i, p := range o {
+		if p.At < 0 || p.At >= day {
+			return fmt.Errorf("point %d at %v is not within a day", i, p.At)
+		}
+		if i > 0 && p.At <= o[i-1].At {
+			return fmt.Errorf("point %d at %v is not after point %d at %v", i, p.At, i-1, o[i-1].At)
+		}
+	}
+
+	return nil
+}
+
+// At returns the value of the curve at the local time of day of t.
+func (o Curve) At(t time.Time) float64 {
+	hour, min, sec := t.Clock()
+
+	return o.Value(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second)
+}
+
+// Value returns the value of the curve at the time since midnight.
+func (o Curve) Value(at time.Duration) float64 {
+	if len(o) == 0 {
+		return 0
+	}
+
+	// Find the points before and after, wrapping around midnight.
+	prev, next := o[len(o)-1], o[0]
+	prev.At -= day
+
+	for _, p := range o {
+		if p.At > at {
+			next = p
+			break
+		}
+
+		prev = p
+		next = o[0]
+		next.At += day
+	}
+
+	if next.At == prev.At {
+		return prev.Value
+	}
+
+	t := float64(at-prev.At) / float64(next.At-prev.At)
+
+	return prev.Value + (next.Value-prev.Value)*t
+}
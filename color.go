@@ -0,0 +1,244 @@
+package controlifx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultKelvin is the color temperature of colors converted from color spaces that have none.
+const DefaultKelvin = 3500
+
+// RGB is a color in the sRGB color space with 8 bits per channel.
+type RGB struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+// ParseHex parses a color in the "#ff8800", "ff8800" or "#f80" form.
+func ParseHex(s string) (RGB, error) {
+	hexStr := strings.TrimPrefix(s, "#")
+	if len(hexStr) == 3 {
+		hexStr = string([]byte{hexStr[0], hexStr[0], hexStr[1], hexStr[1], hexStr[2], hexStr[2]})
+	}
+
+	if len(hexStr) != 6 {
+		return RGB{}, fmt.Errorf("invalid hex color %q", s)
+	}
+
+	b, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return RGB{}, fmt.Errorf("invalid hex color %q", s)
+	}
+
+	return RGB{b[0], b[1], b[2]}, nil
+}
+
+// Hex returns the color in the "#ff8800" form.
+func (o RGB) Hex() string {
+	return "#" + hex.EncodeToString([]byte{o.R, o.G, o.B})
+}
+
+// HSBK converts the color to HSBK with DefaultKelvin.
+func (o RGB) HSBK() HSBK {
+	h, s, v := rgbToHsv(float64(o.R)/0xff, float64(o.G)/0xff, float64(o.B)/0xff)
+
+	return HSBK{
+		Hue:        quantizeHue(h),
+		Saturation: quantize(s),
+		Brightness: quantize(v),
+		Kelvin:     DefaultKelvin,
+	}
+}
+
+// RGB converts the hue, saturation and brightness of the color to RGB, ignoring the color temperature.
+func (o HSBK) RGB() RGB {
+	r, g, b := o.rgb()
+
+	return RGB{
+		R: uint8(math.Round(r * 0xff)),
+		G: uint8(math.Round(g * 0xff)),
+		B: uint8(math.Round(b * 0xff)),
+	}
+}
+
+// rgb returns the gamma-encoded RGB components of the color in the range [0, 1].
+func (o HSBK) rgb() (r, g, b float64) {
+	return hsvToRgb(float64(o.Hue)/0x10000*360, float64(o.Saturation)/0xffff, float64(o.Brightness)/0xffff)
+}
+
+// HSBKFromHSV returns the color with the hue in degrees and the saturation and value in percent, with DefaultKelvin.
+// Hues outside of [0, 360) wrap around and the other components are clamped.
+func HSBKFromHSV(hue, saturation, value float64) HSBK {
+	return HSBK{
+		Hue:        quantizeHue(hue),
+		Saturation: quantize(saturation / 100),
+		Brightness: quantize(value / 100),
+		Kelvin:     DefaultKelvin,
+	}
+}
+
+// HSV returns the hue of the color in degrees and the saturation and brightness in percent.
+func (o HSBK) HSV() (hue, saturation, value float64) {
+	return float64(o.Hue) / 0x10000 * 360, float64(o.Saturation) / 0xffff * 100, float64(o.Brightness) / 0xffff * 100
+}
+
+// sRGB primaries with a D65 white point.
+var (
+	linearRgbToXyz = [3][3]float64{
+		{0.4124564, 0.3575761, 0.1804375},
+		{0.2126729, 0.7151522, 0.0721750},
+		{0.0193339, 0.1191920, 0.9503041},
+	}
+	xyzToLinearRgb = [3][3]float64{
+		{3.2404542, -1.5371385, -0.4985314},
+		{-0.9692660, 1.8760108, 0.0415560},
+		{0.0556434, -0.2040259, 1.0572252},
+	}
+)
+
+// Chromaticity of the D65 white point.
+const (
+	d65X = 0.3127
+	d65Y = 0.3290
+)
+
+// HSBKFromXY returns the color with the CIE 1931 chromaticity and brightness in the range [0, 1], with
+// DefaultKelvin. Chromaticities outside of the sRGB gamut are clipped.
+func HSBKFromXY(x, y, brightness float64) HSBK {
+	if y <= 0 {
+		return HSBK{Brightness: quantize(brightness), Kelvin: DefaultKelvin}
+	}
+
+	r, g, b := mul(xyzToLinearRgb, x/y, 1, (1-x-y)/y)
+	r, g, b = math.Max(r, 0), math.Max(g, 0), math.Max(b, 0)
+
+	if m := math.Max(r, math.Max(g, b)); m > 0 {
+		r, g, b = r/m, g/m, b/m
+	}
+
+	h, s, _ := rgbToHsv(srgbGamma(r), srgbGamma(g), srgbGamma(b))
+
+	return HSBK{
+		Hue:        quantizeHue(h),
+		Saturation: quantize(s),
+		Brightness: quantize(brightness),
+		Kelvin:     DefaultKelvin,
+	}
+}
+
+// XY returns the CIE 1931 chromaticity of the color, ignoring the color temperature. Black has the chromaticity of
+// the D65 white point.
+func (o HSBK) XY() (x, y float64) {
+	r, g, b := o.rgb()
+	return linearRgbToXy(srgbLinear(r), srgbLinear(g), srgbLinear(b))
+}
+
+func linearRgbToXy(r, g, b float64) (x, y float64) {
+	bigX, bigY, bigZ := mul(linearRgbToXyz, r, g, b)
+
+	sum := bigX + bigY + bigZ
+	if sum == 0 {
+		return d65X, d65Y
+	}
+
+	return bigX / sum, bigY / sum
+}
+
+func mul(m [3][3]float64, a, b, c float64) (float64, float64, float64) {
+	return m[0][0]*a + m[0][1]*b + m[0][2]*c,
+		m[1][0]*a + m[1][1]*b + m[1][2]*c,
+		m[2][0]*a + m[2][1]*b + m[2][2]*c
+}
+
+// srgbLinear converts a gamma-encoded sRGB component to linear light.
+func srgbLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// srgbGamma converts a linear light component to gamma-encoded sRGB.
+func srgbGamma(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// rgbToHsv converts RGB components in the range [0, 1] to the hue in degrees and the saturation and value in the
+// range [0, 1].
+func rgbToHsv(r, g, b float64) (h, s, v float64) {
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	d := max - min
+
+	v = max
+	if max > 0 {
+		s = d / max
+	}
+
+	switch {
+	case d == 0:
+		h = 0
+	case max == r:
+		h = 60 * math.Mod((g-b)/d, 6)
+	case max == g:
+		h = 60 * ((b-r)/d + 2)
+	default:
+		h = 60 * ((r-g)/d + 4)
+	}
+
+	if h < 0 {
+		h += 360
+	}
+
+	return
+}
+
+// hsvToRgb converts the hue in degrees and the saturation and value in the range [0, 1] to RGB components in the
+// range [0, 1].
+func hsvToRgb(h, s, v float64) (r, g, b float64) {
+	c := v * s
+	hh := math.Mod(h, 360) / 60
+	x := c * (1 - math.Abs(math.Mod(hh, 2)-1))
+
+	switch {
+	case hh < 1:
+		r, g, b = c, x, 0
+	case hh < 2:
+		r, g, b = x, c, 0
+	case hh < 3:
+		r, g, b = 0, c, x
+	case hh < 4:
+		r, g, b = 0, x, c
+	case hh < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	m := v - c
+
+	return r + m, g + m, b + m
+}
+
+// quantize converts a component in the range [0, 1] to the range [0, 0xffff], clamping values outside of it.
+func quantize(v float64) uint16 {
+	return uint16(math.Round(math.Max(0, math.Min(1, v)) * 0xffff))
+}
+
+// quantizeHue converts a hue in degrees to the range [0, 0xffff], where 0x10000 would be 360 degrees.
+func quantizeHue(h float64) uint16 {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	return uint16(int(math.Round(h/360*0x10000)) & 0xffff)
+}
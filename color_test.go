@@ -0,0 +1,151 @@
+package controlifx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseHex(t *testing.T) {
+	tests := map[string]RGB{
+		"#ff8800": {0xff, 0x88, 0x00},
+		"FF8800":  {0xff, 0x88, 0x00},
+		"#f80":    {0xff, 0x88, 0x00},
+	}
+
+	for s, expected := range tests {
+		c, err := ParseHex(s)
+		if err != nil {
+			t.Error("error:", err)
+		}
+
+		if expected != c {
+			t.Errorf("%s: expected '%#v', got '%#v'", s, expected, c)
+		}
+	}
+
+	for _, s := range []string{"", "#ff88", "#ff880g", "#ff88000"} {
+		if _, err := ParseHex(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestRGB_Hex(t *testing.T) {
+	if expected, s := "#ff8800", (RGB{0xff, 0x88, 0x00}).Hex(); expected != s {
+		t.Errorf("expected '%s', got '%s'", expected, s)
+	}
+}
+
+func TestRGB_HSBK(t *testing.T) {
+	tests := map[RGB]HSBK{
+		{0xff, 0x00, 0x00}: {0, 0xffff, 0xffff, DefaultKelvin},
+		{0x00, 0xff, 0x00}: {21845, 0xffff, 0xffff, DefaultKelvin},
+		{0x00, 0x00, 0xff}: {43691, 0xffff, 0xffff, DefaultKelvin},
+		{0xff, 0x88, 0x00}: {5825, 0xffff, 0xffff, DefaultKelvin},
+		{0xff, 0xff, 0xff}: {0, 0, 0xffff, DefaultKelvin},
+		{0x80, 0x80, 0x80}: {0, 0, 0x8080, DefaultKelvin},
+		{0x00, 0x00, 0x00}: {0, 0, 0, DefaultKelvin},
+		{0x80, 0x00, 0x40}: {60075, 0xffff, 0x8080, DefaultKelvin},
+	}
+
+	for c, expected := range tests {
+		if hsbk := c.HSBK(); expected != hsbk {
+			t.Errorf("%s: expected '%#v', got '%#v'", c.Hex(), expected, hsbk)
+		}
+	}
+}
+
+func TestHSBK_RGB(t *testing.T) {
+	// Every 8-bit color must survive a round trip through HSBK.
+	for r := 0; r < 0x100; r += 5 {
+		for g := 0; g < 0x100; g += 3 {
+			for b := 0; b < 0x100; b += 7 {
+				c := RGB{uint8(r), uint8(g), uint8(b)}
+
+				if rgb := c.HSBK().RGB(); c != rgb {
+					t.Fatalf("expected '%s', got '%s'", c.Hex(), rgb.Hex())
+				}
+			}
+		}
+	}
+}
+
+func TestHSBKFromHSV(t *testing.T) {
+	tests := []struct {
+		h, s, v  float64
+		expected HSBK
+	}{
+		{0, 100, 100, HSBK{0, 0xffff, 0xffff, DefaultKelvin}},
+		{360, 100, 100, HSBK{0, 0xffff, 0xffff, DefaultKelvin}},
+		{-90, 50, 50, HSBK{49152, 0x8000, 0x8000, DefaultKelvin}},
+		{120, 150, -10, HSBK{21845, 0xffff, 0, DefaultKelvin}},
+		{359.999, 100, 100, HSBK{0, 0xffff, 0xffff, DefaultKelvin}},
+	}
+
+	for _, test := range tests {
+		if hsbk := HSBKFromHSV(test.h, test.s, test.v); test.expected != hsbk {
+			t.Errorf("%v/%v/%v: expected '%#v', got '%#v'", test.h, test.s, test.v, test.expected, hsbk)
+		}
+	}
+
+	h, s, v := (HSBK{49152, 0xffff, 0, DefaultKelvin}).HSV()
+	if h != 270 || s != 100 || v != 0 {
+		t.Errorf("expected '270/100/0', got '%v/%v/%v'", h, s, v)
+	}
+}
+
+func TestHSBK_XY(t *testing.T) {
+	tests := []struct {
+		c    RGB
+		x, y float64
+	}{
+		{RGB{0xff, 0x00, 0x00}, 0.6400, 0.3300},
+		{RGB{0x00, 0xff, 0x00}, 0.3000, 0.6000},
+		{RGB{0x00, 0x00, 0xff}, 0.1500, 0.0600},
+		{RGB{0xff, 0xff, 0xff}, 0.3127, 0.3290},
+		{RGB{0x00, 0x00, 0x00}, 0.3127, 0.3290},
+	}
+
+	for _, test := range tests {
+		x, y := test.c.HSBK().XY()
+		if math.Abs(test.x-x) > 0.0005 || math.Abs(test.y-y) > 0.0005 {
+			t.Errorf("%s: expected '%.4f,%.4f', got '%.4f,%.4f'", test.c.Hex(), test.x, test.y, x, y)
+		}
+	}
+}
+
+func TestHSBKFromXY(t *testing.T) {
+	tests := []struct {
+		x, y     float64
+		expected RGB
+	}{
+		{0.6400, 0.3300, RGB{0xff, 0x00, 0x00}},
+		{0.3000, 0.6000, RGB{0x00, 0xff, 0x00}},
+		{0.1500, 0.0600, RGB{0x00, 0x00, 0xff}},
+		{0.3127, 0.3290, RGB{0xff, 0xff, 0xff}},
+	}
+
+	for _, test := range tests {
+		c := HSBKFromXY(test.x, test.y, 1).RGB()
+		if math.Abs(float64(test.expected.R)-float64(c.R)) > 2 ||
+			math.Abs(float64(test.expected.G)-float64(c.G)) > 2 ||
+			math.Abs(float64(test.expected.B)-float64(c.B)) > 2 {
+			t.Errorf("%v,%v: expected '%s', got '%s'", test.x, test.y, test.expected.Hex(), c.Hex())
+		}
+	}
+}
+
+func TestLookupCSSColor(t *testing.T) {
+	c, ok := LookupCSSColor("RebeccaPurple")
+	if !ok {
+		t.Fatal("color not found")
+	}
+
+	if expected := (RGB{0x66, 0x33, 0x99}); expected != c {
+		t.Errorf("expected '%s', got '%s'", expected.Hex(), c.Hex())
+	}
+
+	if _, ok := LookupCSSColor("notacolor"); ok {
+		t.Error("expected no color")
+	}
+}
@@ -1,8 +1,11 @@
 package controlifx
 
 import (
+	"context"
+	"errors"
 	"math/rand"
 	"net"
+	"sync"
 	"time"
 )
 
@@ -13,6 +16,18 @@ const (
 	MaxReadSize    = LanHeaderSize + 64
 	DefaultPort    = 56700
 	DefaultPortStr = "56700"
+
+	// DefaultRetries is the number of times a message is resent to a device that hasn't responded yet.
+	DefaultRetries = 3
+)
+
+var (
+	// ErrNoResponse is returned when a device didn't respond to a message.
+	ErrNoResponse = errors.New("no response from device")
+
+	// ErrNotConnected is returned by the methods of a Connection that wasn't created by Connect, ManualConnect or
+	// ConnectFrom.
+	ErrNotConnected = errors.New("not connected")
 )
 
 type (
@@ -28,13 +43,19 @@ type (
 		// Addr is the remote address of the device.
 		Addr *net.UDPAddr
 		// Mac is the MAC address of the device.
-		Mac uint64
+		Mac MAC
 	}
 
-	// Connection is the connection between the client and the network devices.
+	// Connection is the connection between the client and the network devices. Methods that wait for responses are
+	// serialized, so a Connection may be shared between goroutines.
 	Connection struct {
+		// Strict makes the connection validate payloads that implement Validator before sending them, and refuse
+		// to send invalid ones.
+		Strict bool
+
 		bcastAddr *net.UDPAddr
 		conn      *net.UDPConn
+		mu        *sync.Mutex
 	}
 )
 
@@ -53,16 +74,45 @@ func ManualConnect(bcastAddr *net.UDPAddr) (o Connection, err error) {
 		return
 	}
 
+	return ConnectFrom(laddr, bcastAddr)
+}
+
+// ConnectFrom connects from the local address instead of DefaultPort on all interfaces, for example when another
+// client has that port. Devices send the states they broadcast to DefaultPort, so Listen only receives responses on
+// other ports.
+func ConnectFrom(laddr, bcastAddr *net.UDPAddr) (o Connection, err error) {
 	if o.conn, err = net.ListenUDP("udp", laddr); err != nil {
 		return
 	}
 
 	o.bcastAddr = bcastAddr
+	o.mu = &sync.Mutex{}
 
 	return
 }
 
+// lock locks the connection for a method that waits for responses.
+func (o Connection) lock() error {
+	if o.mu == nil {
+		return ErrNotConnected
+	}
+
+	o.mu.Lock()
+
+	return nil
+}
+
 func (o Connection) send(addr *net.UDPAddr, msg SendableLanMessage) error {
+	if o.conn == nil {
+		return ErrNotConnected
+	}
+
+	if v, ok := msg.Payload.(Validator); ok && o.Strict {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+
 	b, err := msg.MarshalBinary()
 	if err != nil {
 		return err
@@ -102,6 +152,11 @@ func (o Connection) Close() error {
 
 // DiscoverDevices discovers as many devices as possible on the network within the timeout and filters devices.
 func (o Connection) DiscoverDevices(timeout int, filter DiscoverFilter) (devices []Device, err error) {
+	if err = o.lock(); err != nil {
+		return
+	}
+	defer o.mu.Unlock()
+
 	getServiceMsg := GetService()
 	getServiceMsg.Header.Frame.Source = rand.Uint32()
 
@@ -128,7 +183,7 @@ func (o Connection) DiscoverDevices(timeout int, filter DiscoverFilter) (devices
 
 		d := Device{
 			Addr: raddr,
-			Mac:  recMsg.Header.FrameAddress.Target,
+			Mac:  MAC(recMsg.Header.FrameAddress.Target),
 		}
 
 		if filter == nil {
@@ -161,7 +216,7 @@ func (o Connection) SendTo(msg SendableLanMessage, devices []Device) error {
 	// msg.Header.Frame.Tagged = true
 
 	for _, d := range devices {
-		msg.Header.FrameAddress.Target = d.Mac
+		msg.Header.FrameAddress.Target = uint64(d.Mac)
 
 		if err := o.send(d.Addr, msg); err != nil {
 			return err
@@ -180,14 +235,25 @@ func (o Connection) SendToAll(msg SendableLanMessage) error {
 }
 
 // SendToAndGet sends the message to the devices, filters the responses, and builds a mapping between a responding
-// device and its response.
+// device and its response. It waits up to NormalTimeout milliseconds for the responses, so that devices that don't
+// respond don't hold up other users of the connection.
 func (o Connection) SendToAndGet(msg SendableLanMessage, devices []Device, filter Filter) (recMsgs map[Device]ReceivableLanMessage, err error) {
+	if err = o.lock(); err != nil {
+		return
+	}
+	defer o.mu.Unlock()
+
 	msg.Header.Frame.Source = rand.Uint32()
 
 	if err = o.SendTo(msg, devices); err != nil {
 		return
 	}
 
+	o.conn.SetReadDeadline(time.Now().Add(NormalTimeout * time.Millisecond))
+	// Remove read deadline.
+	defer o.conn.SetReadDeadline(time.Time{})
+
+	devices = append([]Device(nil), devices...)
 	recMsgs = make(map[Device]ReceivableLanMessage)
 
 	for len(devices) > 0 {
@@ -203,7 +269,7 @@ func (o Connection) SendToAndGet(msg SendableLanMessage, devices []Device, filte
 		}
 
 		for i, d := range devices {
-			if d.Mac == recMsg.Header.FrameAddress.Target {
+			if d.Mac == MAC(recMsg.Header.FrameAddress.Target) {
 				recMsgs[d] = recMsg
 				devices = append(devices[:i], devices[i+1:]...)
 				break
@@ -217,6 +283,11 @@ func (o Connection) SendToAndGet(msg SendableLanMessage, devices []Device, filte
 // SendToAllAndGet sends the message to all devices on the network, filters the responses, and builds a mapping between
 // a responding device and its response.
 func (o Connection) SendToAllAndGet(timeout int, msg SendableLanMessage, filter Filter) (recMsgs map[Device]ReceivableLanMessage, err error) {
+	if err = o.lock(); err != nil {
+		return
+	}
+	defer o.mu.Unlock()
+
 	msg.Header.Frame.Source = rand.Uint32()
 
 	if err = o.SendToAll(msg); err != nil {
@@ -241,7 +312,7 @@ func (o Connection) SendToAllAndGet(timeout int, msg SendableLanMessage, filter
 
 		d := Device{
 			Addr: raddr,
-			Mac:  recMsg.Header.FrameAddress.Target,
+			Mac:  MAC(recMsg.Header.FrameAddress.Target),
 		}
 
 		recMsgs[d] = recMsg
@@ -250,6 +321,189 @@ func (o Connection) SendToAllAndGet(timeout int, msg SendableLanMessage, filter
 	return
 }
 
+// SendToAndGetContext sends the message to the devices, filters the responses, and builds a mapping between a
+// responding device and its response. The message is resent to devices that haven't responded within NormalTimeout
+// milliseconds, up to DefaultRetries times or until the context is done. Devices that never respond are left out of
+// the mapping.
+func (o Connection) SendToAndGetContext(ctx context.Context, msg SendableLanMessage, devices []Device, filter Filter) (recMsgs map[Device]ReceivableLanMessage, err error) {
+	return o.sendEachAndGet(ctx, func(Device) SendableLanMessage {
+		return msg
+	}, devices, filter)
+}
+
+// sendEachAndGet is SendToAndGetContext with a message of its own for each device, so that the devices are sent
+// different payloads at once.
+func (o Connection) sendEachAndGet(ctx context.Context, msgFor func(d Device) SendableLanMessage, devices []Device, filter Filter) (recMsgs map[Device]ReceivableLanMessage, err error) {
+	exchanges := make([]exchange, len(devices))
+	for i, d := range devices {
+		exchanges[i] = exchange{device: d, msg: msgFor(d), filter: filter}
+	}
+
+	responses, err := o.exchange(ctx, exchanges)
+
+	recMsgs = make(map[Device]ReceivableLanMessage, len(responses))
+	for i, recMsg := range responses {
+		recMsgs[devices[i]] = recMsg
+	}
+
+	return
+}
+
+// exchange is a message to a device and the filter that its response passes.
+type exchange struct {
+	device Device
+	msg    SendableLanMessage
+	filter Filter
+}
+
+// exchange sends the messages and returns the responses by the index of their exchange. A response belongs to an
+// exchange with the device whose filter it passes, so the filters of exchanges with the same device shouldn't
+// overlap. Messages to the same device are spaced so that it receives no more than MessageRate messages per second,
+// while the responses to earlier ones are received. Messages are resent like by SendToAndGetContext.
+func (o Connection) exchange(ctx context.Context, exchanges []exchange) (responses map[int]ReceivableLanMessage, err error) {
+	if err = o.lock(); err != nil {
+		return
+	}
+	defer o.mu.Unlock()
+
+	source := rand.Uint32()
+	sequence := uint8(rand.Uint32())
+
+	pending := make(map[int]bool, len(exchanges))
+	for i := range exchanges {
+		pending[i] = true
+	}
+
+	responses = make(map[int]ReceivableLanMessage)
+
+	// Remove read deadline.
+	defer o.conn.SetReadDeadline(time.Time{})
+
+	for attempt := 0; attempt <= DefaultRetries && len(pending) > 0; attempt++ {
+		// The n-th pending message to each device goes into the n-th wave.
+		var waves [][]int
+		n := make(map[MAC]int)
+		for i := range exchanges {
+			if !pending[i] {
+				continue
+			}
+
+			mac := exchanges[i].device.Mac
+			if n[mac] == len(waves) {
+				waves = append(waves, nil)
+			}
+			waves[n[mac]] = append(waves[n[mac]], i)
+			n[mac]++
+		}
+
+		for w, wave := range waves {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+
+			for _, i := range wave {
+				msg := exchanges[i].msg
+				msg.Header.Frame.Source = source
+				msg.Header.FrameAddress.Sequence = sequence
+				msg.Header.FrameAddress.Target = uint64(exchanges[i].device.Mac)
+
+				if err = o.send(exchanges[i].device.Addr, msg); err != nil {
+					return
+				}
+			}
+
+			deadline := time.Now().Add(time.Second / MessageRate)
+			if w == len(waves)-1 {
+				deadline = time.Now().Add(NormalTimeout * time.Millisecond)
+			}
+			if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+				deadline = ctxDeadline
+			}
+
+			if err = o.receiveResponses(deadline, source, exchanges, pending, responses); err != nil {
+				return
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		err = ctx.Err()
+	}
+
+	return
+}
+
+// receiveResponses receives responses to the pending exchanges until the deadline or until none are pending.
+func (o Connection) receiveResponses(deadline time.Time, source uint32, exchanges []exchange, pending map[int]bool, responses map[int]ReceivableLanMessage) error {
+	o.conn.SetReadDeadline(deadline)
+
+	for len(pending) > 0 {
+		recMsg, _, err := o.receive(func(recMsg ReceivableLanMessage) bool {
+			return checkSourceAndFilter(recMsg, source, nil)
+		})
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return nil
+			}
+
+			return err
+		}
+
+		for i := range pending {
+			e := exchanges[i]
+			if e.device.Mac == MAC(recMsg.Header.FrameAddress.Target) && (e.filter == nil || e.filter(recMsg)) {
+				responses[i] = recMsg
+				delete(pending, i)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// Listen waits up to timeout milliseconds for a message that passes the filter without sending anything, such as a
+// state that a device broadcasts when it's changed by another client. Messages from any source are received. ok is
+// false if no message was received in time or before the context is done. The connection can't be used by others
+// until then.
+func (o Connection) Listen(ctx context.Context, timeout int, filter Filter) (recMsg ReceivableLanMessage, d Device, ok bool, err error) {
+	if err = o.lock(); err != nil {
+		return
+	}
+	defer o.mu.Unlock()
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Millisecond)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	o.conn.SetReadDeadline(deadline)
+
+	// Remove read deadline.
+	defer o.conn.SetReadDeadline(time.Time{})
+
+	recMsg, raddr, err := o.receive(func(recMsg ReceivableLanMessage) bool {
+		return filter == nil || filter(recMsg)
+	})
+	if err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			err = ctx.Err()
+		}
+
+		return
+	}
+
+	d = Device{
+		Addr: raddr,
+		Mac:  MAC(recMsg.Header.FrameAddress.Target),
+	}
+
+	return recMsg, d, true, nil
+}
+
 // TypeFilter filters out responses that do not have the payload type.
 func TypeFilter(t uint16) Filter {
 	return func(msg ReceivableLanMessage) bool {
@@ -0,0 +1,166 @@
+package controlifx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+// testConnection returns a connection on a free loopback port, so that tests neither need nor disturb DefaultPort.
+// The connection must be closed.
+func testConnection(t *testing.T) Connection {
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+	conn, err := ConnectFrom(loopback, loopback)
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+
+	return conn
+}
+
+// testLight returns a fake device with the MAC address and its Device. The fake device must be closed.
+func testLight(t *testing.T, mac uint64) (*fakelight.Light, Device) {
+	f, err := fakelight.New(mac)
+	if err != nil {
+		t.Fatal("cannot start fake device:", err)
+	}
+
+	return f, Device{Addr: f.Addr(), Mac: MAC(mac)}
+}
+
+func TestConnection_ZeroValue(t *testing.T) {
+	var conn Connection
+
+	if _, err := conn.SendToAndGetContext(context.Background(), LightGet(), nil, nil); err != ErrNotConnected {
+		t.Errorf("expected '%v', got '%v'", ErrNotConnected, err)
+	}
+
+	if err := conn.SendToAll(LightGet()); err != ErrNotConnected {
+		t.Errorf("expected '%v', got '%v'", ErrNotConnected, err)
+	}
+
+	if _, _, _, err := conn.Listen(context.Background(), 1, nil); err != ErrNotConnected {
+		t.Errorf("expected '%v', got '%v'", ErrNotConnected, err)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestConnection_SendToAndGetContext(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	a, da := testLight(t, 1)
+	defer a.Close()
+	b, db := testLight(t, 2)
+	defer b.Close()
+	c, dc := testLight(t, 3)
+	defer c.Close()
+
+	a.Update(func(s *fakelight.State) { s.Power = OnPowerLevel })
+	// b answers after two retries, c never does.
+	b.Drop(LightGetPowerType, 2)
+	c.Drop(LightGetPowerType, -1)
+
+	recMsgs, err := conn.SendToAndGetContext(context.Background(), LightGetPower(), []Device{da, db, dc},
+		TypeFilter(LightStatePowerType))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(recMsgs) != 2 {
+		t.Fatalf("expected '2' responses, got '%d'", len(recMsgs))
+	}
+
+	// Each response is matched to the device that sent it.
+	if level := recMsgs[da].Payload.(*LightStatePowerLanMessage).Level; level != OnPowerLevel {
+		t.Errorf("expected '%#x', got '%#x'", OnPowerLevel, level)
+	}
+	if level := recMsgs[db].Payload.(*LightStatePowerLanMessage).Level; level != OffPowerLevel {
+		t.Errorf("expected '%#x', got '%#x'", OffPowerLevel, level)
+	}
+	if _, ok := recMsgs[dc]; ok {
+		t.Error("expected no response from", dc)
+	}
+
+	tests := []struct {
+		light    *fakelight.Light
+		expected int
+	}{
+		{a, 1},
+		{b, 3},
+		{c, DefaultRetries + 1},
+	}
+
+	for _, test := range tests {
+		if n := test.light.Count(LightGetPowerType); n != test.expected {
+			t.Errorf("%x: expected '%d' messages, got '%d'", test.light.Mac, test.expected, n)
+		}
+	}
+}
+
+func TestConnection_SendToAndGetContext_Cancel(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	f, d := testLight(t, 1)
+	defer f.Close()
+	f.Drop(LightGetPowerType, -1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := conn.SendToAndGetContext(ctx, LightGetPower(), []Device{d}, nil); err != context.Canceled {
+		t.Errorf("expected '%v', got '%v'", context.Canceled, err)
+	}
+}
+
+func TestConnection_SendToAndGet(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	a, da := testLight(t, 1)
+	defer a.Close()
+	b, db := testLight(t, 2)
+	defer b.Close()
+
+	// a never answers, which mustn't keep the connection locked for the second caller.
+	a.Drop(LightGetPowerType, -1)
+
+	done := make(chan error, 1)
+	go func() {
+		recMsgs, err := conn.SendToAndGet(LightGetPower(), []Device{da}, nil)
+		if err == nil && len(recMsgs) != 0 {
+			err = fmt.Errorf("expected no responses, got '%d'", len(recMsgs))
+		}
+		done <- err
+	}()
+
+	for a.Count(LightGetPowerType) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	recMsgs, err := conn.SendToAndGet(LightGetPower(), []Device{db}, TypeFilter(LightStatePowerType))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, ok := recMsgs[db]; !ok {
+		t.Error("expected a response from", db)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the first caller to time out")
+	}
+}
@@ -0,0 +1,130 @@
+package controlifx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DeviceInfo describes a device as gathered by Describe.
+type DeviceInfo struct {
+	Device       Device
+	Version      StateVersionLanMessage
+	HostFirmware StateHostFirmwareLanMessage
+	Label        string
+	Group        StateGroupLanMessage
+	Location     StateLocationLanMessage
+	Power        uint16
+
+	// Product and Features are only set if the product is known and both the version and the host firmware were
+	// received.
+	Product  *Product
+	Features ProductFeatures
+
+	// Missing are the types of the responses that the device didn't send. The respective fields are left zero.
+	Missing []uint16
+	// Err is non-nil if responses are missing. It wraps ErrNoResponse, or the error that stopped Describe if
+	// queries weren't sent.
+	Err error
+}
+
+var describeQueries = []struct {
+	name string
+	msg  func() SendableLanMessage
+	t    uint16
+}{
+	{"version", GetVersion, StateVersionType},
+	{"host firmware", GetHostFirmware, StateHostFirmwareType},
+	{"label", GetLabel, StateLabelType},
+	{"group", GetGroup, StateGroupType},
+	{"location", GetLocation, StateLocationType},
+	{"power", GetPower, StatePowerType},
+}
+
+// Describe gathers the version, host firmware, label, group, location and power level of the devices. All queries
+// are sent at once, spaced per device so that no device receives more than MessageRate messages per second, and
+// resent to the devices that haven't responded to them like by SendToAndGetContext.
+//
+// A device that doesn't respond to some queries still gets a DeviceInfo with the Missing and Err fields set. If the
+// context is done or a query can't be sent, Describe returns the DeviceInfos with the responses received so far,
+// along with the error. The returned DeviceInfos are in the same order as the devices.
+func (o Connection) Describe(ctx context.Context, devices []Device) (infos []DeviceInfo, err error) {
+	infos = make([]DeviceInfo, len(devices))
+	for i, d := range devices {
+		infos[i].Device = d
+	}
+
+	// The queries of each device are in the order of describeQueries, so that they're spaced in that order.
+	var exchanges []exchange
+	for _, d := range devices {
+		for _, q := range describeQueries {
+			exchanges = append(exchanges, exchange{device: d, msg: q.msg(), filter: TypeFilter(q.t)})
+		}
+	}
+
+	responses, err := o.exchange(ctx, exchanges)
+
+	cause := ErrNoResponse
+	if err != nil {
+		cause = err
+	}
+
+	for i := range infos {
+		info := &infos[i]
+
+		var missing []string
+		for j, q := range describeQueries {
+			recMsg, ok := responses[i*len(describeQueries)+j]
+			if !ok {
+				info.Missing = append(info.Missing, q.t)
+				missing = append(missing, q.name)
+				continue
+			}
+
+			info.merge(recMsg)
+		}
+
+		if len(missing) > 0 {
+			info.Err = fmt.Errorf("%w: %s", cause, strings.Join(missing, ", "))
+		}
+
+		if info.missing(StateVersionType) || info.missing(StateHostFirmwareType) {
+			continue
+		}
+
+		if p, ok := info.Version.LookupProduct(); ok {
+			info.Product = &p
+			info.Features = p.FeaturesAt(info.HostFirmware.Version)
+		}
+	}
+
+	return
+}
+
+// merge sets the field of the response.
+func (o *DeviceInfo) merge(recMsg ReceivableLanMessage) {
+	switch payload := recMsg.Payload.(type) {
+	case *StateVersionLanMessage:
+		o.Version = *payload
+	case *StateHostFirmwareLanMessage:
+		o.HostFirmware = *payload
+	case *StateLabelLanMessage:
+		o.Label = payload.Label
+	case *StateGroupLanMessage:
+		o.Group = *payload
+	case *StateLocationLanMessage:
+		o.Location = *payload
+	case *StatePowerLanMessage:
+		o.Power = payload.Level
+	}
+}
+
+func (o DeviceInfo) missing(t uint16) bool {
+	for _, m := range o.Missing {
+		if m == t {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package controlifx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+// groupPayload returns a StateGroup or StateLocation payload with the ID byte and label.
+func groupPayload(id byte, label string) []byte {
+	b := make([]byte, 56)
+	b[0] = id
+	copy(b[16:48], label)
+
+	return b
+}
+
+func TestConnection_Describe(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	a, da := testLight(t, 1)
+	defer a.Close()
+	b, db := testLight(t, 2)
+	defer b.Close()
+
+	for _, f := range []*fakelight.Light{a, b} {
+		f.Update(func(s *fakelight.State) {
+			s.Vendor, s.Product = 1, 1
+			s.Firmware = uint32(NewFirmwareVersion(2, 1))
+			s.Label = "Kitchen"
+			s.Power = OnPowerLevel
+		})
+		f.Reply(GetGroupType, StateGroupType, groupPayload(1, "Downstairs"))
+		f.Reply(GetLocationType, StateLocationType, groupPayload(2, "Home"))
+	}
+
+	// b never answers some queries.
+	b.Drop(GetLabelType, -1)
+	b.Drop(GetVersionType, -1)
+
+	start := time.Now()
+	infos, err := conn.Describe(context.Background(), []Device{da, db})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The queries run at once, so the retries of the two missing ones are waited through together.
+	if elapsed, retries := time.Since(start), (DefaultRetries+1)*NormalTimeout*time.Millisecond; elapsed >= 2*retries {
+		t.Errorf("expected less than '%v', got '%v'", 2*retries, elapsed)
+	}
+
+	if len(infos) != 2 || infos[0].Device != da || infos[1].Device != db {
+		t.Fatalf("expected infos of '%v' and '%v' in order, got '%v'", da, db, infos)
+	}
+
+	// The replies of each device are merged into its DeviceInfo.
+	info := infos[0]
+	if info.Err != nil || info.Missing != nil {
+		t.Errorf("expected no missing responses, got '%v' (%v)", info.Missing, info.Err)
+	}
+	if info.Label != "Kitchen" || info.Group.Label != "Downstairs" || info.Location.Label != "Home" ||
+		info.Power != OnPowerLevel {
+		t.Errorf("expected the responses to be merged, got '%+v'", info)
+	}
+	if info.Product == nil || info.Product.ProductId != 1 || !info.Features.Color {
+		t.Errorf("expected product 1 and its features, got '%v' and '%+v'", info.Product, info.Features)
+	}
+
+	info = infos[1]
+	if expected := []uint16{StateVersionType, StateLabelType}; !reflect.DeepEqual(expected, info.Missing) {
+		t.Errorf("expected '%v', got '%v'", expected, info.Missing)
+	}
+	if !errors.Is(info.Err, ErrNoResponse) || info.Err.Error() != "no response from device: version, label" {
+		t.Errorf("expected '%v: version, label', got '%v'", ErrNoResponse, info.Err)
+	}
+	if info.Group.Label != "Downstairs" || info.Product != nil {
+		t.Errorf("expected the group without a product, got '%+v'", info)
+	}
+}
+
+func TestConnection_Describe_Cancel(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	f, d := testLight(t, 1)
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel once the label was asked for, after the version and host firmware were answered.
+	f.Update(func(s *fakelight.State) { s.Vendor, s.Product = 1, 1 })
+	f.Drop(GetLabelType, -1)
+	go func() {
+		for f.Count(GetLabelType) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	infos, err := conn.Describe(ctx, []Device{d})
+	if err != context.Canceled {
+		t.Fatalf("expected '%v', got '%v'", context.Canceled, err)
+	}
+
+	if len(infos) != 1 {
+		t.Fatalf("expected '1' info, got '%d'", len(infos))
+	}
+
+	// The responses that arrived are kept.
+	info := infos[0]
+	if len(info.Missing) == 0 || info.missing(StateVersionType) || info.missing(StateHostFirmwareType) ||
+		!info.missing(StateLabelType) {
+		t.Errorf("expected the label missing but not the version and host firmware, got '%v'", info.Missing)
+	}
+	if info.Product == nil || info.Product.ProductId != 1 {
+		t.Errorf("expected product 1, got '%v'", info.Product)
+	}
+	if !errors.Is(info.Err, context.Canceled) {
+		t.Errorf("expected '%v', got '%v'", context.Canceled, info.Err)
+	}
+}
@@ -0,0 +1,121 @@
+package controlifx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// MAC is the MAC address of a device in the little-endian layout of LanHeaderFrameAddress.Target, so the first byte
+// of the address is stored in the least significant byte.
+type MAC uint64
+
+// ParseMAC parses a MAC address in either the "d0:73:d5:12:34:56" (colons or dashes) or the "d073d5123456" serial
+// form.
+func ParseMAC(s string) (MAC, error) {
+	hexStr := s
+	if len(s) == 17 {
+		sep := s[2]
+		if sep != ':' && sep != '-' {
+			return 0, fmt.Errorf("invalid MAC address %q", s)
+		}
+
+		parts := strings.Split(s, string(sep))
+		if len(parts) != 6 {
+			return 0, fmt.Errorf("invalid MAC address %q", s)
+		}
+		for _, part := range parts {
+			if len(part) != 2 {
+				return 0, fmt.Errorf("invalid MAC address %q", s)
+			}
+		}
+		hexStr = strings.Join(parts, "")
+	}
+
+	if len(hexStr) != 12 {
+		return 0, fmt.Errorf("invalid MAC address %q", s)
+	}
+
+	b, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid MAC address %q", s)
+	}
+
+	var mac MAC
+	for i, v := range b {
+		mac |= MAC(v) << (8 * uint(i))
+	}
+
+	return mac, nil
+}
+
+func (o MAC) bytes() []byte {
+	b := make([]byte, 6)
+	for i := range b {
+		b[i] = byte(o >> (8 * uint(i)))
+	}
+
+	return b
+}
+
+// String returns the MAC address in the "d0:73:d5:12:34:56" form.
+func (o MAC) String() string {
+	return net.HardwareAddr(o.bytes()).String()
+}
+
+// Serial returns the MAC address in the "d073d5123456" form printed on devices.
+func (o MAC) Serial() string {
+	return hex.EncodeToString(o.bytes())
+}
+
+func (o MAC) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *MAC) UnmarshalText(text []byte) (err error) {
+	*o, err = ParseMAC(string(text))
+
+	return
+}
+
+// ParseDevice parses a device in the "serial@ip[:port]" form, where the serial is anything accepted by ParseMAC and
+// the port defaults to DefaultPort. This allows talking to devices without discovering them first.
+func ParseDevice(s string) (d Device, err error) {
+	i := strings.LastIndexByte(s, '@')
+	if i < 0 {
+		err = fmt.Errorf("invalid device %q: missing '@'", s)
+		return
+	}
+
+	if d.Mac, err = ParseMAC(s[:i]); err != nil {
+		return
+	}
+
+	host, port := s[i+1:], DefaultPortStr
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		host = strings.Trim(host, "[]")
+	} else if host, port, err = net.SplitHostPort(host); err != nil {
+		err = fmt.Errorf("invalid device %q: %v", s, err)
+		return
+	}
+
+	if _, err = strconv.ParseUint(port, 10, 16); err != nil {
+		err = fmt.Errorf("invalid device %q: invalid port %q", s, port)
+		return
+	}
+
+	d.Addr, err = net.ResolveUDPAddr("udp", net.JoinHostPort(host, port))
+
+	return
+}
+
+// String returns the device in the "serial@ip:port" form understood by ParseDevice.
+func (o Device) String() string {
+	if o.Addr == nil {
+		return o.Mac.Serial()
+	}
+
+	return o.Mac.Serial() + "@" + o.Addr.String()
+}
@@ -0,0 +1,126 @@
+package controlifx
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+)
+
+func TestParseMAC(t *testing.T) {
+	expected := MAC(0x563412d573d0)
+
+	for _, s := range []string{"d0:73:d5:12:34:56", "D0-73-D5-12-34-56", "d073d5123456"} {
+		mac, err := ParseMAC(s)
+		if err != nil {
+			t.Error("error:", err)
+		}
+
+		if expected != mac {
+			t.Errorf("%s: expected '%#x', got '%#x'", s, expected, mac)
+		}
+	}
+}
+
+func TestParseMAC2(t *testing.T) {
+	for _, s := range []string{"", "d073d512345", "d0:73:d5:12:34", "d0:73-d5:12:34:56", "d073d512345g",
+		"d0:73:d5:1:234:56"} {
+		if _, err := ParseMAC(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestMAC_String(t *testing.T) {
+	o := MAC(0x563412d573d0)
+
+	if expected := "d0:73:d5:12:34:56"; expected != o.String() {
+		t.Errorf("expected '%s', got '%s'", expected, o.String())
+	}
+
+	if expected := "d073d5123456"; expected != o.Serial() {
+		t.Errorf("expected '%s', got '%s'", expected, o.Serial())
+	}
+}
+
+func TestMAC_MarshalJSON(t *testing.T) {
+	o := struct{ Mac MAC }{0x563412d573d0}
+
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Error("error:", err)
+	}
+
+	if expected := `{"Mac":"d0:73:d5:12:34:56"}`; expected != string(b) {
+		t.Errorf("expected '%s', got '%s'", expected, b)
+	}
+
+	o.Mac = 0
+	if err := json.Unmarshal(b, &o); err != nil {
+		t.Error("error:", err)
+	}
+
+	if expected := MAC(0x563412d573d0); expected != o.Mac {
+		t.Errorf("expected '%#x', got '%#x'", expected, o.Mac)
+	}
+}
+
+func TestParseDevice(t *testing.T) {
+	tests := map[string]Device{
+		"d073d5123456@10.0.0.23": {
+			Addr: &net.UDPAddr{IP: net.ParseIP("10.0.0.23"), Port: DefaultPort},
+			Mac:  0x563412d573d0,
+		},
+		"d0:73:d5:12:34:56@10.0.0.23:56701": {
+			Addr: &net.UDPAddr{IP: net.ParseIP("10.0.0.23"), Port: 56701},
+			Mac:  0x563412d573d0,
+		},
+		"d073d5123456@[fe80::1]:56701": {
+			Addr: &net.UDPAddr{IP: net.ParseIP("fe80::1"), Port: 56701},
+			Mac:  0x563412d573d0,
+		},
+		"d073d5123456@fe80::1": {
+			Addr: &net.UDPAddr{IP: net.ParseIP("fe80::1"), Port: DefaultPort},
+			Mac:  0x563412d573d0,
+		},
+	}
+
+	for s, expected := range tests {
+		d, err := ParseDevice(s)
+		if err != nil {
+			t.Error("error:", err)
+			continue
+		}
+
+		if expected.Mac != d.Mac || !expected.Addr.IP.Equal(d.Addr.IP) || expected.Addr.Port != d.Addr.Port {
+			t.Errorf("%s: expected '%s', got '%s'", s, expected, d)
+		}
+	}
+}
+
+func TestParseDevice2(t *testing.T) {
+	for _, s := range []string{"d073d5123456", "d073d5123456@10.0.0.23:port", "d073d5123456@10.0.0.23:70000", "xyz@10.0.0.23"} {
+		if _, err := ParseDevice(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestDevice_String(t *testing.T) {
+	o := Device{
+		Addr: &net.UDPAddr{IP: net.ParseIP("10.0.0.23"), Port: DefaultPort},
+		Mac:  0x563412d573d0,
+	}
+
+	if expected := "d073d5123456@10.0.0.23:56700"; expected != o.String() {
+		t.Errorf("expected '%s', got '%s'", expected, o.String())
+	}
+
+	d, err := ParseDevice(o.String())
+	if err != nil {
+		t.Error("error:", err)
+	}
+
+	if o.Mac != d.Mac || o.Addr.String() != d.Addr.String() {
+		t.Errorf("expected '%s', got '%s'", o, d)
+	}
+}
@@ -0,0 +1,128 @@
+// Package effects runs animated effects such as breathing, strobing or color cycling on LIFX devices.
+package effects
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/yath/controlifx"
+)
+
+// NoWaveform is the Waveform of effects that devices can't run natively.
+const NoWaveform = -1
+
+// Effect is an animation that alternates the color of a light between its original color and the effect color.
+type Effect struct {
+	Name string
+
+	// Waveform is the waveform with which devices run the effect natively, or NoWaveform if the effect is only run
+	// client-side.
+	Waveform int
+	// SkewRatio is the skew ratio of the native waveform in the range [0, 1].
+	SkewRatio float64
+
+	// Instant is set if client-side frames should be shown immediately instead of fading into each other.
+	Instant bool
+	// Frame returns the color of a light at the phase in the range [0, 1) of a cycle, given its original color and
+	// the effect color. It's used to run the effect client-side.
+	Frame func(from, to controlifx.HSBK, phase float64, rnd *rand.Rand) controlifx.HSBK
+}
+
+var (
+	// Breathe fades smoothly to the effect color and back.
+	Breathe = Effect{
+		Name:      "breathe",
+		Waveform:  controlifx.SineWaveform,
+		SkewRatio: 0.5,
+		Frame: func(from, to controlifx.HSBK, phase float64, _ *rand.Rand) controlifx.HSBK {
+			return controlifx.Interpolate(from, to, (1-math.Cos(2*math.Pi*phase))/2, controlifx.HSBKInterpolation)
+		},
+	}
+
+	// Pulse switches to the effect color for the first half of each cycle.
+	Pulse = Effect{
+		Name:      "pulse",
+		Waveform:  controlifx.PulseWaveform,
+		SkewRatio: 0.5,
+		Instant:   true,
+		Frame: func(from, to controlifx.HSBK, phase float64, _ *rand.Rand) controlifx.HSBK {
+			if phase < 0.5 {
+				return to
+			}
+
+			return from
+		},
+	}
+
+	// Strobe flashes the effect color for a tenth of each cycle and is dark otherwise.
+	Strobe = Effect{
+		Name:     "strobe",
+		Waveform: NoWaveform,
+		Instant:  true,
+		Frame: func(_, to controlifx.HSBK, phase float64, _ *rand.Rand) controlifx.HSBK {
+			if phase < 0.1 {
+				return to
+			}
+
+			to.Brightness = 0
+			return to
+		},
+	}
+
+	// Candle flickers randomly around the effect color.
+	Candle = Effect{
+		Name:     "candle",
+		Waveform: NoWaveform,
+		Frame: func(_, to controlifx.HSBK, _ float64, rnd *rand.Rand) controlifx.HSBK {
+			to.Brightness = uint16(float64(to.Brightness) * (0.6 + 0.4*rnd.Float64()))
+			if to.Kelvin > 100 {
+				to.Kelvin -= uint16(rnd.Intn(100))
+			}
+
+			return to
+		},
+	}
+
+	// ColorCycle moves through the hue circle once per cycle, starting with the hue of the effect color.
+	ColorCycle = Effect{
+		Name:     "colorcycle",
+		Waveform: NoWaveform,
+		Frame: func(_, to controlifx.HSBK, phase float64, _ *rand.Rand) controlifx.HSBK {
+			to.Hue += uint16(phase * 0x10000)
+
+			return to
+		},
+	}
+)
+
+var registry = map[string]Effect{
+	Breathe.Name:    Breathe,
+	Pulse.Name:      Pulse,
+	Strobe.Name:     Strobe,
+	Candle.Name:     Candle,
+	ColorCycle.Name: ColorCycle,
+}
+
+// Lookup returns the effect with the name.
+func Lookup(name string) (Effect, bool) {
+	e, ok := registry[name]
+
+	return e, ok
+}
+
+// Names returns the names of all effects in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// SkewRatio converts a ratio in the range [0, 1] to the range of LightSetWaveformLanMessage.SkewRatio.
+func SkewRatio(ratio float64) int16 {
+	return int16(int32(math.Round(math.Max(0, math.Min(1, ratio))*0xffff)) - 0x8000)
+}
@@ -0,0 +1,76 @@
+package effects
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/yath/controlifx"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range Names() {
+		e, ok := Lookup(name)
+		if !ok || e.Name != name {
+			t.Errorf("%s: not found", name)
+		}
+	}
+
+	if _, ok := Lookup("disco"); ok {
+		t.Error("expected no effect")
+	}
+
+	expected := []string{"breathe", "candle", "colorcycle", "pulse", "strobe"}
+	if names := Names(); !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected '%v', got '%v'", expected, names)
+	}
+}
+
+func TestSkewRatio(t *testing.T) {
+	tests := map[float64]int16{
+		0:   -0x8000,
+		0.5: 0,
+		1:   0x7fff,
+		2:   0x7fff,
+	}
+
+	for ratio, expected := range tests {
+		if v := SkewRatio(ratio); expected != v {
+			t.Errorf("%v: expected '%d', got '%d'", ratio, expected, v)
+		}
+	}
+}
+
+func TestEffect_Frame(t *testing.T) {
+	from := controlifx.HSBK{Hue: 0, Saturation: 0, Brightness: 0x8000, Kelvin: 3500}
+	to := controlifx.HSBK{Hue: 0x8000, Saturation: 0xffff, Brightness: 0xffff, Kelvin: 3500}
+	rnd := rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		effect   Effect
+		phase    float64
+		expected controlifx.HSBK
+	}{
+		{Breathe, 0, from},
+		{Breathe, 0.5, to},
+		{Pulse, 0.25, to},
+		{Pulse, 0.75, from},
+		{Strobe, 0.05, to},
+		{Strobe, 0.5, controlifx.HSBK{Hue: 0x8000, Saturation: 0xffff, Brightness: 0, Kelvin: 3500}},
+		{ColorCycle, 0.25, controlifx.HSBK{Hue: 0xc000, Saturation: 0xffff, Brightness: 0xffff, Kelvin: 3500}},
+		{ColorCycle, 0.5, controlifx.HSBK{Hue: 0, Saturation: 0xffff, Brightness: 0xffff, Kelvin: 3500}},
+	}
+
+	for _, test := range tests {
+		if c := test.effect.Frame(from, to, test.phase, rnd); test.expected != c {
+			t.Errorf("%s at %v: expected '%#v', got '%#v'", test.effect.Name, test.phase, test.expected, c)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		c := Candle.Frame(from, to, 0, rnd)
+		if c.Hue != to.Hue || c.Brightness > to.Brightness || c.Brightness < to.Brightness/2 || c.Kelvin > to.Kelvin {
+			t.Fatalf("unexpected candle frame '%#v'", c)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package effects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/clock"
+)
+
+// DefaultFlashes is the number of flashes of notifications whose options don't specify one.
+const DefaultFlashes = 3
+
+// NotifyOptions configure a notification.
+type NotifyOptions struct {
+	// Color is the color of the flashes.
+	Color controlifx.HSBK
+	// Flashes is the number of flashes.
+	Flashes int
+	// Period is the duration of a flash and the following dark phase.
+	Period time.Duration
+	// RestoreDuration is the duration of the transition back to the original state.
+	RestoreDuration time.Duration
+}
+
+// Notify flashes the lights in the color and then restores their exact prior color and power. Lights that are off
+// are turned on for the notification. Every step waits for the acknowledgements of the devices and resends messages
+// that got lost, and the prior state is restored even if the context is done in the middle of the notification.
+// Devices that don't acknowledge a step still take part in the rest, and Notify returns an error wrapping
+// ErrNoResponse that lists them.
+func Notify(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device, opts NotifyOptions) (err error) {
+	if opts.Flashes <= 0 {
+		opts.Flashes = DefaultFlashes
+	}
+	if opts.Period <= 0 {
+		opts.Period = DefaultPeriod
+	}
+
+	states, err := Snapshot(ctx, conn, devices)
+	if err != nil {
+		return
+	}
+
+	var targets, off []controlifx.Device
+	for _, d := range devices {
+		state, ok := states[d.Mac]
+		if !ok {
+			continue
+		}
+
+		targets = append(targets, d)
+		if state.Power == controlifx.OffPowerLevel {
+			off = append(off, d)
+		}
+	}
+
+	if len(targets) == 0 {
+		return controlifx.ErrNoResponse
+	}
+
+	defer func() {
+		restoreCtx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+		defer cancel()
+
+		if restoreErr := Restore(restoreCtx, conn, targets, states, opts.RestoreDuration); err == nil {
+			err = restoreErr
+		}
+	}()
+
+	// Devices that miss a step still take part in the rest, and are reported at the end.
+	var missed []controlifx.Device
+	seen := make(map[controlifx.MAC]bool)
+	step := func(devices []controlifx.Device, msg controlifx.SendableLanMessage) error {
+		m, err := setAll(ctx, conn, devices, msg)
+		for _, d := range m {
+			if !seen[d.Mac] {
+				seen[d.Mac] = true
+				missed = append(missed, d)
+			}
+		}
+
+		if errors.Is(err, controlifx.ErrNoResponse) {
+			return nil
+		}
+
+		return err
+	}
+
+	dark := opts.Color
+	dark.Brightness = 0
+
+	// Start dark so that lights that are off don't show their old color when turned on.
+	if err = step(targets, setColor(dark)); err != nil {
+		return
+	}
+
+	if err = step(off, controlifx.LightSetPower(controlifx.LightSetPowerLanMessage{
+		Level: controlifx.OnPowerLevel,
+	})); err != nil {
+		return
+	}
+
+	for i := 0; i < opts.Flashes; i++ {
+		if err = step(targets, setColor(opts.Color)); err != nil {
+			return
+		}
+
+		if err = clock.Sleep(ctx, opts.Period/2); err != nil {
+			return
+		}
+
+		if err = step(targets, setColor(dark)); err != nil {
+			return
+		}
+
+		if err = clock.Sleep(ctx, opts.Period/2); err != nil {
+			return
+		}
+	}
+
+	if len(missed) > 0 {
+		err = fmt.Errorf("%w: %s", controlifx.ErrNoResponse, devicesString(missed))
+	}
+
+	return
+}
+
+func setColor(c controlifx.HSBK) controlifx.SendableLanMessage {
+	return controlifx.LightSetColor(controlifx.LightSetColorLanMessage{
+		Color: c,
+	})
+}
+
+// setAll sends the message to all devices at once and waits for their acknowledgements. Devices that don't
+// acknowledge the message don't stop the others; they're returned, and setAll only fails if none acknowledged.
+func setAll(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device, msg controlifx.SendableLanMessage) (missed []controlifx.Device, err error) {
+	if len(devices) == 0 {
+		return
+	}
+
+	msg.Header.FrameAddress.AckRequired = true
+
+	recMsgs, err := conn.SendToAndGetContext(ctx, msg, devices, controlifx.TypeFilter(controlifx.AcknowledgementType))
+	if err != nil {
+		return
+	}
+
+	for _, d := range devices {
+		if _, ok := recMsgs[d]; !ok {
+			missed = append(missed, d)
+		}
+	}
+
+	if len(missed) == len(devices) {
+		err = fmt.Errorf("%w: none of %d devices acknowledged", controlifx.ErrNoResponse, len(devices))
+	}
+
+	return
+}
+
+func devicesString(devices []controlifx.Device) string {
+	names := make([]string, len(devices))
+	for i, d := range devices {
+		names[i] = d.String()
+	}
+
+	return strings.Join(names, ", ")
+}
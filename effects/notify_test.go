@@ -0,0 +1,86 @@
+package effects
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestNotify(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	a, da := testLight(t, 1)
+	defer a.Close()
+	b, db := testLight(t, 2)
+	defer b.Close()
+
+	// b is off and misses every message that would turn it on.
+	b.Update(func(s *fakelight.State) { s.Power = controlifx.OffPowerLevel })
+	b.Drop(controlifx.LightSetPowerType, -1)
+
+	err := Notify(context.Background(), conn, []controlifx.Device{da, db}, NotifyOptions{
+		Color:   controlifx.HSBK{Saturation: 0xffff, Brightness: 0xffff, Kelvin: 3500},
+		Flashes: 2,
+		Period:  50 * time.Millisecond,
+	})
+	if !errors.Is(err, controlifx.ErrNoResponse) || !strings.Contains(err.Error(), db.String()) {
+		t.Errorf("expected '%v: %v', got '%v'", controlifx.ErrNoResponse, db, err)
+	}
+
+	// Dark, two flashes each followed by dark, and the restore.
+	if n := a.Count(controlifx.LightSetColorType); n != 6 {
+		t.Errorf("expected '6' colors, got '%d'", n)
+	}
+	// a was on, so its power is only restored.
+	if n := a.Count(controlifx.LightSetPowerType); n != 1 {
+		t.Errorf("expected '1' power, got '%d'", n)
+	}
+	// b still flashes even though it missed being turned on.
+	if n := b.Count(controlifx.LightSetColorType); n != 6 {
+		t.Errorf("expected '6' colors, got '%d'", n)
+	}
+
+	expected := fakelight.State{Color: fakelight.HSBK{Brightness: 0xffff, Kelvin: 3500}, Power: controlifx.OnPowerLevel}
+	if s := a.State(); s != expected {
+		t.Errorf("expected '%+v', got '%+v'", expected, s)
+	}
+	if c := b.State().Color; c != expected.Color {
+		t.Errorf("expected '%+v', got '%+v'", expected.Color, c)
+	}
+}
+
+func TestNotify_Cancel(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	f, d := testLight(t, 1)
+	defer f.Close()
+	f.Update(func(s *fakelight.State) { s.Power = controlifx.OffPowerLevel })
+	before := f.State()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	err := Notify(ctx, conn, []controlifx.Device{d}, NotifyOptions{
+		Color:   controlifx.HSBK{Saturation: 0xffff, Brightness: 0xffff, Kelvin: 3500},
+		Flashes: 10,
+		Period:  100 * time.Millisecond,
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected '%v', got '%v'", context.DeadlineExceeded, err)
+	}
+
+	// The light was turned on for the notification and is off again.
+	if n := f.Count(controlifx.LightSetPowerType); n != 2 {
+		t.Errorf("expected '2' powers, got '%d'", n)
+	}
+	if s := f.State(); s != before {
+		t.Errorf("expected '%+v', got '%+v'", before, s)
+	}
+}
@@ -0,0 +1,352 @@
+package effects
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yath/controlifx"
+)
+
+const (
+	// DefaultPeriod is the period of effects whose options don't specify one.
+	DefaultPeriod = time.Second
+
+	// MinFrameInterval is the shortest interval between client-side frames that doesn't exceed the message rate of
+	// devices.
+	MinFrameInterval = time.Second / controlifx.MessageRate
+
+	// DefaultFrameInterval is the interval between client-side frames whose options don't specify one.
+	DefaultFrameInterval = 2 * MinFrameInterval
+
+	// restoreTimeout is how long restoring the state of the lights may take after the effect has finished.
+	restoreTimeout = 5 * time.Second
+)
+
+// Options configure how an effect runs.
+type Options struct {
+	// Color is the effect color.
+	Color controlifx.HSBK
+	// Period is the duration of a cycle.
+	Period time.Duration
+	// Cycles is the number of cycles to run, or 0 to run until the effect is stopped.
+	Cycles float64
+
+	// FrameInterval is the interval between frames of effects run client-side. It's raised to MinFrameInterval.
+	FrameInterval time.Duration
+	// ClientSide forces the effect to run client-side even on devices that could run it natively.
+	ClientSide bool
+	// Features are the features of the devices, by MAC address. The features of devices not in here are queried.
+	Features map[controlifx.MAC]controlifx.ProductFeatures
+
+	// PowerOn turns on lights that are off for the duration of the effect.
+	PowerOn bool
+	// RestoreDuration is the duration of the transition back to the original state.
+	RestoreDuration time.Duration
+}
+
+// Run is a running effect.
+type Run struct {
+	// Devices are the devices the effect runs on.
+	Devices []controlifx.Device
+	// Skipped are the devices whose state couldn't be read, so the effect doesn't run on them.
+	Skipped []controlifx.Device
+
+	conn    controlifx.Connection
+	effect  Effect
+	opts    Options
+	states  map[controlifx.MAC]controlifx.LightStateLanMessage
+	native  map[controlifx.MAC]bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+	errOnce sync.Once
+	err     error
+}
+
+// Start snapshots the state of the devices and starts running the effect on them in the background. Devices whose
+// product and firmware support the effect's waveform run it natively with LightSetWaveform; all others, and devices
+// that don't acknowledge the waveform, are sent a frame at each FrameInterval. When the effect has run the number of
+// cycles, or when it's stopped or the context is done, the original color and power of the lights are restored.
+func Start(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device, effect Effect, opts Options) (*Run, error) {
+	if opts.Period <= 0 {
+		opts.Period = DefaultPeriod
+	}
+	if opts.FrameInterval <= 0 {
+		opts.FrameInterval = DefaultFrameInterval
+	}
+	if opts.FrameInterval < MinFrameInterval {
+		opts.FrameInterval = MinFrameInterval
+	}
+
+	states, err := Snapshot(ctx, conn, devices)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Run{
+		conn:   conn,
+		effect: effect,
+		opts:   opts,
+		states: states,
+		native: make(map[controlifx.MAC]bool),
+		done:   make(chan struct{}),
+	}
+
+	for _, d := range devices {
+		if _, ok := states[d.Mac]; !ok {
+			o.Skipped = append(o.Skipped, d)
+			continue
+		}
+
+		o.Devices = append(o.Devices, d)
+	}
+
+	if len(o.Devices) == 0 {
+		return nil, controlifx.ErrNoResponse
+	}
+
+	if effect.Waveform != NoWaveform && !opts.ClientSide {
+		var unknown []controlifx.Device
+		for _, d := range o.Devices {
+			if f, ok := opts.Features[d.Mac]; ok {
+				o.native[d.Mac] = SupportsWaveforms(f)
+			} else {
+				unknown = append(unknown, d)
+			}
+		}
+
+		// Devices whose features can't be resolved run the effect client-side.
+		fs, err := features(ctx, conn, unknown)
+		if err != nil {
+			return nil, err
+		}
+		for mac, f := range fs {
+			o.native[mac] = SupportsWaveforms(f)
+		}
+	}
+
+	if opts.PowerOn {
+		for _, d := range o.Devices {
+			if states[d.Mac].Power == controlifx.OffPowerLevel {
+				if err := controlifx.NewLight(conn, d).SetPower(ctx, true, 0); err != nil {
+					o.setErr(err)
+				}
+			}
+		}
+	}
+
+	ctx, o.cancel = context.WithCancel(ctx)
+	go o.run(ctx)
+
+	return o, nil
+}
+
+// SupportsWaveforms returns whether a device with the features, as resolved for its firmware, can run waveforms
+// natively, which all devices that emit light can.
+func SupportsWaveforms(features controlifx.ProductFeatures) bool {
+	return features.MaxKelvin > 0
+}
+
+// Snapshot returns the state of the devices that responded to LightGet, by MAC address.
+func Snapshot(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device) (map[controlifx.MAC]controlifx.LightStateLanMessage, error) {
+	recMsgs, err := conn.SendToAndGetContext(ctx, controlifx.LightGet(), devices,
+		controlifx.TypeFilter(controlifx.LightStateType))
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[controlifx.MAC]controlifx.LightStateLanMessage, len(recMsgs))
+	for d, recMsg := range recMsgs {
+		states[d.Mac] = *recMsg.Payload.(*controlifx.LightStateLanMessage)
+	}
+
+	return states, nil
+}
+
+// features returns the features of the devices that responded to GetVersion and GetHostFirmware and whose product is
+// known, by MAC address.
+func features(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device) (map[controlifx.MAC]controlifx.ProductFeatures, error) {
+	versions, err := conn.SendToAndGetContext(ctx, controlifx.GetVersion(), devices,
+		controlifx.TypeFilter(controlifx.StateVersionType))
+	if err != nil {
+		return nil, err
+	}
+
+	firmwares, err := conn.SendToAndGetContext(ctx, controlifx.GetHostFirmware(), devices,
+		controlifx.TypeFilter(controlifx.StateHostFirmwareType))
+	if err != nil {
+		return nil, err
+	}
+
+	fs := make(map[controlifx.MAC]controlifx.ProductFeatures, len(devices))
+	for _, d := range devices {
+		version, ok := versions[d]
+		firmware, ok2 := firmwares[d]
+		if !ok || !ok2 {
+			continue
+		}
+
+		f, err := controlifx.ResolveFeatures(*version.Payload.(*controlifx.StateVersionLanMessage),
+			*firmware.Payload.(*controlifx.StateHostFirmwareLanMessage))
+		if err == nil {
+			fs[d.Mac] = f
+		}
+	}
+
+	return fs, nil
+}
+
+// Restore sets the color and power of the devices back to the snapshotted state.
+func Restore(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device, states map[controlifx.MAC]controlifx.LightStateLanMessage, duration time.Duration) error {
+	var errs []string
+
+	for _, d := range devices {
+		state, ok := states[d.Mac]
+		if !ok {
+			continue
+		}
+
+		light := controlifx.NewLight(conn, d)
+
+		if err := light.SetColor(ctx, state.Color, duration); err != nil {
+			errs = append(errs, d.String()+": "+err.Error())
+			continue
+		}
+
+		if err := light.SetPower(ctx, state.Power != controlifx.OffPowerLevel, duration); err != nil {
+			errs = append(errs, d.String()+": "+err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return &RestoreError{Errors: errs}
+	}
+
+	return nil
+}
+
+// RestoreError lists the devices whose state couldn't be restored.
+type RestoreError struct {
+	Errors []string
+}
+
+func (o *RestoreError) Error() string {
+	return "cannot restore state: " + strings.Join(o.Errors, "; ")
+}
+
+// Stop stops the effect, restores the original state of the lights and returns the same as Wait.
+func (o *Run) Stop() error {
+	o.cancel()
+
+	return o.Wait()
+}
+
+// Wait waits until the effect has finished and the original state is restored. It returns the first error that
+// occurred while running the effect.
+func (o *Run) Wait() error {
+	<-o.done
+
+	return o.err
+}
+
+// Done is closed when the effect has finished and the original state is restored.
+func (o *Run) Done() <-chan struct{} {
+	return o.done
+}
+
+func (o *Run) setErr(err error) {
+	o.errOnce.Do(func() {
+		o.err = err
+	})
+}
+
+func (o *Run) run(ctx context.Context) {
+	defer close(o.done)
+	defer o.cancel()
+
+	var clientSide []controlifx.Device
+
+	for _, d := range o.Devices {
+		if !o.native[d.Mac] {
+			clientSide = append(clientSide, d)
+			continue
+		}
+
+		if err := controlifx.NewLight(o.conn, d).Set(ctx, o.waveform()); err != nil {
+			// Fall back to running the effect client-side.
+			clientSide = append(clientSide, d)
+		}
+	}
+
+	var end <-chan time.Time
+	if o.opts.Cycles > 0 {
+		timer := time.NewTimer(time.Duration(o.opts.Cycles * float64(o.opts.Period)))
+		defer timer.Stop()
+
+		end = timer.C
+	}
+
+	ticker := time.NewTicker(o.opts.FrameInterval)
+	defer ticker.Stop()
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	start := time.Now()
+
+loop:
+	for {
+		for _, d := range clientSide {
+			if err := o.conn.SendTo(o.frame(d, time.Since(start), rnd), []controlifx.Device{d}); err != nil {
+				o.setErr(err)
+				break loop
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-end:
+			break loop
+		case <-ticker.C:
+		}
+	}
+
+	restoreCtx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+	defer cancel()
+
+	if err := Restore(restoreCtx, o.conn, o.Devices, o.states, o.opts.RestoreDuration); err != nil {
+		o.setErr(err)
+	}
+}
+
+func (o *Run) waveform() controlifx.SendableLanMessage {
+	cycles := float32(o.opts.Cycles)
+	if cycles <= 0 {
+		cycles = math.MaxFloat32
+	}
+
+	return controlifx.LightSetWaveform(controlifx.LightSetWaveformLanMessage{
+		Transient: true,
+		Color:     o.opts.Color,
+		Period:    controlifx.DurationToMs(o.opts.Period),
+		Cycles:    cycles,
+		SkewRatio: SkewRatio(o.effect.SkewRatio),
+		Waveform:  uint8(o.effect.Waveform),
+	})
+}
+
+func (o *Run) frame(d controlifx.Device, elapsed time.Duration, rnd *rand.Rand) controlifx.SendableLanMessage {
+	phase := math.Mod(float64(elapsed)/float64(o.opts.Period), 1)
+
+	var duration time.Duration
+	if !o.effect.Instant {
+		duration = o.opts.FrameInterval
+	}
+
+	return controlifx.LightSetColor(controlifx.LightSetColorLanMessage{
+		Color:    o.effect.Frame(o.states[d.Mac].Color, o.opts.Color, phase, rnd),
+		Duration: controlifx.DurationToMs(duration),
+	})
+}
@@ -0,0 +1,92 @@
+package effects
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+// testConnection returns a connection on a free loopback port. It must be closed.
+func testConnection(t *testing.T) controlifx.Connection {
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+	conn, err := controlifx.ConnectFrom(loopback, loopback)
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+
+	return conn
+}
+
+// testLight returns a fake light that is on, and its Device. It must be closed.
+func testLight(t *testing.T, mac uint64) (*fakelight.Light, controlifx.Device) {
+	f, err := fakelight.New(mac)
+	if err != nil {
+		t.Fatal("cannot start fake device:", err)
+	}
+
+	f.Update(func(s *fakelight.State) {
+		s.Color = fakelight.HSBK{Brightness: 0xffff, Kelvin: 3500}
+		s.Power = controlifx.OnPowerLevel
+	})
+
+	return f, controlifx.Device{Addr: f.Addr(), Mac: controlifx.MAC(mac)}
+}
+
+func TestStart(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	a, da := testLight(t, 1)
+	defer a.Close()
+	b, db := testLight(t, 2)
+	defer b.Close()
+	c, dc := testLight(t, 3)
+	defer c.Close()
+
+	for _, f := range []*fakelight.Light{a, b} {
+		f.Update(func(s *fakelight.State) { s.Vendor, s.Product = 1, 27 })
+	}
+	// b should run waveforms but doesn't acknowledge them, so it's sent frames instead.
+	b.Drop(controlifx.LightSetWaveformType, -1)
+	// c is an unknown product, so it's sent frames without trying the waveform.
+
+	run, err := Start(context.Background(), conn, []controlifx.Device{da, db, dc}, Breathe, Options{
+		Color:  controlifx.HSBK{Hue: 0x8000, Saturation: 0xffff, Brightness: 0xffff, Kelvin: 3500},
+		Period: 300 * time.Millisecond,
+		Cycles: 1,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := run.Wait(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if n := a.Count(controlifx.LightSetWaveformType); n != 1 {
+		t.Errorf("expected '1' waveform, got '%d'", n)
+	}
+	// Only the restore.
+	if n := a.Count(controlifx.LightSetColorType); n != 1 {
+		t.Errorf("expected '1' color, got '%d'", n)
+	}
+	for _, f := range []*fakelight.Light{b, c} {
+		if n := f.Count(controlifx.LightSetColorType); n < 3 {
+			t.Errorf("%x: expected frames, got '%d' colors", f.Mac, n)
+		}
+	}
+	if n := c.Count(controlifx.LightSetWaveformType); n != 0 {
+		t.Errorf("expected '0' waveforms, got '%d'", n)
+	}
+
+	for _, f := range []*fakelight.Light{a, b, c} {
+		if c := f.State().Color; c != (fakelight.HSBK{Brightness: 0xffff, Kelvin: 3500}) {
+			t.Errorf("%x: expected the color to be restored, got '%v'", f.Mac, c)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package effects
+
+import (
+	"context"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/clock"
+)
+
+const (
+	// DefaultSunriseDuration is the duration of sunrises whose options don't specify one.
+	DefaultSunriseDuration = 30 * time.Minute
+
+	// defaultSunriseStep is the duration of each transition of sunrises whose options don't specify the number of
+	// steps.
+	defaultSunriseStep = time.Minute
+)
+
+// DefaultSunrise goes from a dark deep red through orange to a bright warm white.
+var DefaultSunrise = []controlifx.HSBK{
+	{Hue: 0, Saturation: 0xffff, Brightness: 0, Kelvin: 2500},
+	// 15°, red-orange.
+	{Hue: 2731, Saturation: 0xffff, Brightness: 0x2000, Kelvin: 2500},
+	// 30°, orange.
+	{Hue: 5461, Saturation: 0xd000, Brightness: 0x6000, Kelvin: 2500},
+	// 38°, amber.
+	{Hue: 6918, Saturation: 0x6000, Brightness: 0xa000, Kelvin: 2700},
+	{Hue: 6918, Saturation: 0, Brightness: 0xffff, Kelvin: 2700},
+}
+
+// SunriseOptions configure a sunrise.
+type SunriseOptions struct {
+	// Colors are the colors the lights go through, evenly spaced over the duration. They're DefaultSunrise if nil.
+	Colors []controlifx.HSBK
+	// Duration is the time from the first to the last color.
+	Duration time.Duration
+	// Steps is the number of transitions, or one per minute of the duration if 0.
+	Steps int
+}
+
+// Sunrise simulates a sunrise on the lights to wake someone up. The lights are set to the first color, turned on if
+// they're off, and then walked through the colors with a series of transitions. After each transition the state of
+// the lights is read back, and lights that were turned off or changed to another color in the meantime are left
+// alone from then on, so that a sunrise can be cancelled with the switch or an app. Sunrise returns when the last
+// color is reached or all lights were interrupted, with the interrupted lights.
+//
+// Each light is sent the colors adapted to its product, so that white-only lights and lights with a narrower color
+// temperature range follow the sunrise in whites, and aren't taken as interrupted for showing what they can.
+func Sunrise(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device, opts SunriseOptions) (interrupted []controlifx.Device, err error) {
+	if opts.Colors == nil {
+		opts.Colors = DefaultSunrise
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultSunriseDuration
+	}
+	if opts.Steps <= 0 {
+		opts.Steps = int(opts.Duration / defaultSunriseStep)
+	}
+	if opts.Steps < 1 {
+		opts.Steps = 1
+	}
+
+	colors := controlifx.Gradient(opts.Colors, opts.Steps+1, controlifx.HSBKInterpolation)
+	step := opts.Duration / time.Duration(opts.Steps)
+
+	states, err := Snapshot(ctx, conn, devices)
+	if err != nil {
+		return
+	}
+
+	var active, off []controlifx.Device
+	for _, d := range devices {
+		state, ok := states[d.Mac]
+		if !ok {
+			continue
+		}
+
+		active = append(active, d)
+		if state.Power == controlifx.OffPowerLevel {
+			off = append(off, d)
+		}
+	}
+
+	if len(active) == 0 {
+		return nil, controlifx.ErrNoResponse
+	}
+
+	fs, err := features(ctx, conn, active)
+	if err != nil {
+		return
+	}
+
+	// adapt returns the color adapted to the device's product, or the color itself if the product is unknown.
+	adapt := func(d controlifx.Device, c controlifx.HSBK) controlifx.HSBK {
+		if f, ok := fs[d.Mac]; ok {
+			c, _ = f.AdaptColor(c)
+		}
+
+		return c
+	}
+
+	// set sends each device the color adapted to its product over the duration. Devices whose product adapts it the
+	// same way are sent it at once.
+	set := func(devices []controlifx.Device, c controlifx.HSBK, duration time.Duration) error {
+		groups := make(map[controlifx.HSBK][]controlifx.Device)
+		for _, d := range devices {
+			adapted := adapt(d, c)
+			groups[adapted] = append(groups[adapted], d)
+		}
+
+		for adapted, ds := range groups {
+			if _, err := setAll(ctx, conn, ds, controlifx.LightSetColor(controlifx.LightSetColorLanMessage{
+				Color:    adapted,
+				Duration: controlifx.DurationToMs(duration),
+			})); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err = set(active, colors[0], 0); err != nil {
+		return
+	}
+
+	if _, err = setAll(ctx, conn, off, controlifx.LightSetPower(controlifx.LightSetPowerLanMessage{
+		Level: controlifx.OnPowerLevel,
+	})); err != nil {
+		return
+	}
+
+	for i := 1; i < len(colors); i++ {
+		if err = set(active, colors[i], step); err != nil {
+			return
+		}
+
+		if err = clock.Sleep(ctx, step); err != nil {
+			return
+		}
+
+		if states, err = Snapshot(ctx, conn, active); err != nil {
+			return
+		}
+
+		still := active[:0]
+		for _, d := range active {
+			// Lights that didn't respond are kept, since they haven't been seen to change.
+			if state, ok := states[d.Mac]; ok && changed(state, adapt(d, colors[i-1]), adapt(d, colors[i])) {
+				interrupted = append(interrupted, d)
+				continue
+			}
+
+			still = append(still, d)
+		}
+		active = still
+
+		if len(active) == 0 {
+			return
+		}
+	}
+
+	return
+}
+
+// changed returns whether the light was turned off or isn't in the transition from one color to another.
+func changed(state controlifx.LightStateLanMessage, from, to controlifx.HSBK) bool {
+	return state.Power == controlifx.OffPowerLevel || !state.Color.Between(from, to)
+}
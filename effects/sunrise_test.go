@@ -0,0 +1,100 @@
+package effects
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestChanged(t *testing.T) {
+	from := controlifx.HSBK{Hue: 0xf000, Saturation: 0xffff, Brightness: 0x1000, Kelvin: 2500}
+	to := controlifx.HSBK{Hue: 0x1000, Saturation: 0xffff, Brightness: 0x2000, Kelvin: 2500}
+
+	on := func(c controlifx.HSBK) controlifx.LightStateLanMessage {
+		return controlifx.LightStateLanMessage{Color: c, Power: controlifx.OnPowerLevel}
+	}
+
+	tests := []struct {
+		name     string
+		state    controlifx.LightStateLanMessage
+		expected bool
+	}{
+		{"at the end", on(to), false},
+		// The hue crosses 0 on the shorter arc.
+		{"in transition", on(controlifx.HSBK{Hue: 0x0100, Saturation: 0xffff, Brightness: 0x1800, Kelvin: 2500}), false},
+		{"turned off", controlifx.LightStateLanMessage{Color: to}, true},
+		{"brighter", on(controlifx.HSBK{Hue: 0x1000, Saturation: 0xffff, Brightness: 0xffff, Kelvin: 2500}), true},
+		{"other hue", on(controlifx.HSBK{Hue: 0x8000, Saturation: 0xffff, Brightness: 0x2000, Kelvin: 2500}), true},
+		{"white", on(controlifx.HSBK{Saturation: 0, Brightness: 0x2000, Kelvin: 4000}), true},
+	}
+
+	for _, test := range tests {
+		if v := changed(test.state, from, to); v != test.expected {
+			t.Errorf("%s: expected '%v', got '%v'", test.name, test.expected, v)
+		}
+	}
+}
+
+func TestDefaultSunrise(t *testing.T) {
+	first, last := DefaultSunrise[0], DefaultSunrise[len(DefaultSunrise)-1]
+
+	if first.Brightness != 0 || first.Saturation != 0xffff {
+		t.Errorf("expected a dark saturated first color, got %+v", first)
+	}
+	if last.Brightness != 0xffff || last.Saturation != 0 {
+		t.Errorf("expected a bright white last color, got %+v", last)
+	}
+}
+
+func TestSunrise(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	color, dc := testLight(t, 1)
+	defer color.Close()
+	white, dw := testLight(t, 2)
+	defer white.Close()
+	switched, ds := testLight(t, 3)
+	defer switched.Close()
+
+	color.Update(func(s *fakelight.State) { s.Vendor, s.Product = 1, 27 })
+	// A White 800, which drops the saturation and shows color temperatures from 2700 K.
+	white.Update(func(s *fakelight.State) {
+		s.Vendor, s.Product = 1, 10
+		s.White = true
+	})
+
+	// switched is turned off by hand after the first transition.
+	go func() {
+		for switched.Count(controlifx.LightSetColorType) < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		switched.Update(func(s *fakelight.State) { s.Power = controlifx.OffPowerLevel })
+	}()
+
+	interrupted, err := Sunrise(context.Background(), conn, []controlifx.Device{dc, dw, ds}, SunriseOptions{
+		Duration: 300 * time.Millisecond,
+		Steps:    3,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(interrupted) != 1 || interrupted[0] != ds {
+		t.Errorf("expected '%v', got '%v'", []controlifx.Device{ds}, interrupted)
+	}
+
+	last := DefaultSunrise[len(DefaultSunrise)-1]
+	if v := color.State().Color; v != (fakelight.HSBK{Hue: last.Hue, Brightness: last.Brightness, Kelvin: last.Kelvin}) {
+		t.Errorf("expected '%v', got '%v'", last, v)
+	}
+	if v := white.State().Color; v.Brightness != last.Brightness || v.Kelvin != last.Kelvin {
+		t.Errorf("expected '%v', got '%v'", last, v)
+	}
+	if n := switched.Count(controlifx.LightSetColorType); n != 2 {
+		t.Errorf("expected '2' colors sent to the switched off light, got '%d'", n)
+	}
+}
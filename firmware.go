@@ -0,0 +1,83 @@
+package controlifx
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FirmwareVersion is the version of a device's firmware, with the major version in the upper and the minor version
+// in the lower 16 bits, so versions can be compared with the usual operators.
+type FirmwareVersion uint32
+
+func NewFirmwareVersion(major, minor uint16) FirmwareVersion {
+	return FirmwareVersion(major)<<16 | FirmwareVersion(minor)
+}
+
+func (o FirmwareVersion) Major() uint16 {
+	return uint16(o >> 16)
+}
+
+func (o FirmwareVersion) Minor() uint16 {
+	return uint16(o)
+}
+
+// AtLeast returns whether the version is the same or newer than major.minor.
+func (o FirmwareVersion) AtLeast(major, minor uint16) bool {
+	return o >= NewFirmwareVersion(major, minor)
+}
+
+// String returns the version in the "major.minor" form.
+func (o FirmwareVersion) String() string {
+	return fmt.Sprintf("%d.%d", o.Major(), o.Minor())
+}
+
+// BuildTime returns the time the firmware was built.
+func (o StateHostFirmwareLanMessage) BuildTime() time.Time {
+	return time.Unix(0, int64(o.Build))
+}
+
+// BuildTime returns the time the firmware was built.
+func (o StateWifiFirmwareLanMessage) BuildTime() time.Time {
+	return time.Unix(0, int64(o.Build))
+}
+
+// FeaturesAt returns the features of the product when running the host firmware version. Newer firmware versions
+// may add features or widen the color temperature range.
+func (o Product) FeaturesAt(v FirmwareVersion) ProductFeatures {
+	features := o.Features
+
+	for _, u := range o.Upgrades {
+		if v >= u.Firmware {
+			features = u.Features
+		}
+	}
+
+	return features
+}
+
+// ResolveFeatures returns the features of a device from its StateVersion and StateHostFirmware responses, so that
+// callers can decide which messages the device understands.
+func ResolveFeatures(version StateVersionLanMessage, firmware StateHostFirmwareLanMessage) (ProductFeatures, error) {
+	p, ok := version.LookupProduct()
+	if !ok {
+		return ProductFeatures{}, fmt.Errorf("unknown product %d of vendor %d", version.Product, version.Vendor)
+	}
+
+	return p.FeaturesAt(firmware.Version), nil
+}
+
+// Features queries the product and host firmware of the light and returns its features.
+func (o Light) Features(ctx context.Context) (ProductFeatures, error) {
+	version, err := o.Version(ctx)
+	if err != nil {
+		return ProductFeatures{}, err
+	}
+
+	firmware, err := o.HostFirmware(ctx)
+	if err != nil {
+		return ProductFeatures{}, err
+	}
+
+	return ResolveFeatures(version, firmware)
+}
@@ -0,0 +1,114 @@
+package controlifx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFirmwareVersion(t *testing.T) {
+	o := FirmwareVersion(0x2004d)
+
+	if o.Major() != 2 || o.Minor() != 77 {
+		t.Errorf("expected '2.77', got '%d.%d'", o.Major(), o.Minor())
+	}
+
+	if expected := "2.77"; expected != o.String() {
+		t.Errorf("expected '%s', got '%s'", expected, o.String())
+	}
+
+	if expected := NewFirmwareVersion(2, 77); expected != o {
+		t.Errorf("expected '%s', got '%s'", expected, o)
+	}
+}
+
+func TestFirmwareVersion_AtLeast(t *testing.T) {
+	o := NewFirmwareVersion(2, 77)
+
+	tests := []struct {
+		major, minor uint16
+		expected     bool
+	}{
+		{2, 76, true},
+		{2, 77, true},
+		{2, 78, false},
+		{1, 100, true},
+		{3, 0, false},
+	}
+
+	for _, test := range tests {
+		if test.expected != o.AtLeast(test.major, test.minor) {
+			t.Errorf("%s at least %d.%d: expected '%t'", o, test.major, test.minor, test.expected)
+		}
+	}
+}
+
+func TestStateHostFirmwareLanMessage_BuildTime(t *testing.T) {
+	o := StateHostFirmwareLanMessage{
+		Build: 1468443904000000000,
+	}
+
+	if expected := time.Date(2016, 7, 13, 21, 5, 4, 0, time.UTC); !expected.Equal(o.BuildTime()) {
+		t.Errorf("expected '%s', got '%s'", expected, o.BuildTime().UTC())
+	}
+}
+
+func TestStateHostFirmwareLanMessage_UnmarshalBinary(t *testing.T) {
+	// Firmware 3.70 as a bulb sends it: the build time, 8 reserved bytes, and the minor and major version.
+	b := []byte{0x00, 0x80, 0x4f, 0x70, 0x6e, 0x24, 0x44, 0x16, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46,
+		0x00, 0x03, 0x00}
+
+	var o StateHostFirmwareLanMessage
+	if err := o.UnmarshalBinary(b); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := StateHostFirmwareLanMessage{Build: 1604447424000000000, Version: NewFirmwareVersion(3, 70)}
+	if expected != o {
+		t.Errorf("expected '%+v', got '%+v'", expected, o)
+	}
+
+	var w StateWifiFirmwareLanMessage
+	if err := w.UnmarshalBinary(b); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if w.Version != expected.Version {
+		t.Errorf("expected '%s', got '%s'", expected.Version, w.Version)
+	}
+}
+
+func TestResolveFeatures(t *testing.T) {
+	version := StateVersionLanMessage{
+		Vendor:  1,
+		Product: 32,
+	}
+
+	tests := []struct {
+		firmware          FirmwareVersion
+		extendedMultizone bool
+		minKelvin         uint16
+	}{
+		{NewFirmwareVersion(2, 76), false, 2500},
+		{NewFirmwareVersion(2, 77), true, 2500},
+		{NewFirmwareVersion(2, 80), true, 1500},
+		{NewFirmwareVersion(3, 70), true, 1500},
+	}
+
+	for _, test := range tests {
+		f, err := ResolveFeatures(version, StateHostFirmwareLanMessage{Version: test.firmware})
+		if err != nil {
+			t.Error("error:", err)
+			continue
+		}
+
+		if test.extendedMultizone != f.ExtendedMultizone || test.minKelvin != f.MinKelvin || !f.Multizone {
+			t.Errorf("%s: unexpected features '%#v'", test.firmware, f)
+		}
+	}
+}
+
+func TestResolveFeatures2(t *testing.T) {
+	if _, err := ResolveFeatures(StateVersionLanMessage{Vendor: 1, Product: 0xffff}, StateHostFirmwareLanMessage{}); err == nil {
+		t.Error("expected error")
+	}
+}
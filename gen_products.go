@@ -0,0 +1,181 @@
+//go:build ignore
+// +build ignore
+
+// Generates products_table.go from LIFX's products.json.
+//
+//	go run gen_products.go [-in products.json]
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+)
+
+const productsUrl = "https://raw.githubusercontent.com/LIFX/products/master/products.json"
+
+type features struct {
+	Color             *bool      `json:"color"`
+	Infrared          *bool      `json:"infrared"`
+	Multizone         *bool      `json:"multizone"`
+	ExtendedMultizone *bool      `json:"extended_multizone"`
+	Matrix            *bool      `json:"matrix"`
+	Chain             *bool      `json:"chain"`
+	Hev               *bool      `json:"hev"`
+	Buttons           *bool      `json:"buttons"`
+	Relays            *bool      `json:"relays"`
+	TemperatureRange  *[2]uint16 `json:"temperature_range"`
+}
+
+// merge overrides the features with those set in o.
+func (o features) merge(f features) features {
+	if o.Color != nil {
+		f.Color = o.Color
+	}
+	if o.Infrared != nil {
+		f.Infrared = o.Infrared
+	}
+	if o.Multizone != nil {
+		f.Multizone = o.Multizone
+	}
+	if o.ExtendedMultizone != nil {
+		f.ExtendedMultizone = o.ExtendedMultizone
+	}
+	if o.Matrix != nil {
+		f.Matrix = o.Matrix
+	}
+	if o.Chain != nil {
+		f.Chain = o.Chain
+	}
+	if o.Hev != nil {
+		f.Hev = o.Hev
+	}
+	if o.Buttons != nil {
+		f.Buttons = o.Buttons
+	}
+	if o.Relays != nil {
+		f.Relays = o.Relays
+	}
+	if o.TemperatureRange != nil {
+		f.TemperatureRange = o.TemperatureRange
+	}
+
+	return f
+}
+
+func (o features) goString() string {
+	var b bytes.Buffer
+
+	b.WriteString("ProductFeatures{")
+	for _, f := range []struct {
+		name string
+		v    *bool
+	}{
+		{"Color", o.Color},
+		{"Infrared", o.Infrared},
+		{"Multizone", o.Multizone},
+		{"ExtendedMultizone", o.ExtendedMultizone},
+		{"Matrix", o.Matrix},
+		{"Chain", o.Chain},
+		{"Hev", o.Hev},
+		{"Buttons", o.Buttons},
+		{"Relays", o.Relays},
+	} {
+		if f.v != nil && *f.v {
+			fmt.Fprintf(&b, "%s: true, ", f.name)
+		}
+	}
+	if o.TemperatureRange != nil {
+		fmt.Fprintf(&b, "MinKelvin: %d, MaxKelvin: %d", o.TemperatureRange[0], o.TemperatureRange[1])
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
+type vendor struct {
+	Vid      uint32   `json:"vid"`
+	Name     string   `json:"name"`
+	Defaults features `json:"defaults"`
+	Products []struct {
+		Pid      uint32   `json:"pid"`
+		Name     string   `json:"name"`
+		Features features `json:"features"`
+		Upgrades []struct {
+			Major    uint16   `json:"major"`
+			Minor    uint16   `json:"minor"`
+			Features features `json:"features"`
+		} `json:"upgrades"`
+	} `json:"products"`
+}
+
+func main() {
+	in := flag.String("in", "", "read products.json from this file instead of "+productsUrl)
+	out := flag.String("out", "products_table.go", "output file")
+	flag.Parse()
+
+	var r io.Reader
+	if *in == "" {
+		res, err := http.Get(productsUrl)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer res.Body.Close()
+
+		r = res.Body
+	} else {
+		f, err := os.Open(*in)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	var vendors []vendor
+	if err := json.NewDecoder(r).Decode(&vendors); err != nil {
+		log.Fatalln(err)
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString("// Code generated by gen_products.go; DO NOT EDIT.\n\n")
+	b.WriteString("package controlifx\n\n")
+	b.WriteString("var products = []Product{\n")
+	for _, v := range vendors {
+		for _, p := range v.Products {
+			fmt.Fprintf(&b, "{VendorId: %d, VendorName: %q, ProductId: %d, Name: %q,\n", v.Vid, v.Name, p.Pid, p.Name)
+			f := p.Features.merge(v.Defaults)
+			fmt.Fprintf(&b, "Features: %s,\n", f.goString())
+
+			// Upgrades are cumulative.
+			if len(p.Upgrades) > 0 {
+				b.WriteString("Upgrades: []ProductUpgrade{\n")
+				for _, u := range p.Upgrades {
+					f = u.Features.merge(f)
+					fmt.Fprintf(&b, "{Firmware: NewFirmwareVersion(%d, %d), Features: %s},\n", u.Major, u.Minor, f.goString())
+				}
+				b.WriteString("},\n")
+			}
+			b.WriteString("},\n")
+		}
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatalln(err)
+	}
+}
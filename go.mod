@@ -1,3 +1,5 @@
 module github.com/yath/controlifx
 
 go 1.13
+
+require gopkg.in/yaml.v3 v3.0.1
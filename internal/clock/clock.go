@@ -0,0 +1,24 @@
+// Package clock has helpers for waiting that the packages of the module share.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Sleep waits for the duration, and returns early with the context's error if the context is done first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,322 @@
+// Package fakelight is a LIFX device on the loopback interface for tests. It speaks the LAN protocol on its own rather
+// than through the controlifx package, so that tests don't check the package against itself.
+package fakelight
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"sync"
+)
+
+const headerSize = 36
+
+// Message types handled by the device.
+const (
+	getHostFirmwareType       = 14
+	stateHostFirmwareType     = 15
+	getPowerType              = 20
+	setPowerType              = 21
+	statePowerType            = 22
+	getLabelType              = 23
+	stateLabelType            = 25
+	getVersionType            = 32
+	stateVersionType          = 33
+	getInfoType               = 34
+	stateInfoType             = 35
+	acknowledgementType       = 45
+	lightGetType              = 101
+	lightSetColorType         = 102
+	lightSetDimAbsoluteType   = 104
+	lightSetDimRelativeType   = 105
+	lightStateType            = 107
+	lightGetPowerType         = 116
+	lightSetPowerType         = 117
+	lightStatePowerType       = 118
+	sensorGetAmbientLightType = 401
+	sensorStateAmbientType    = 402
+)
+
+type (
+	// HSBK is a color as sent on the wire. It converts to controlifx.HSBK.
+	HSBK struct {
+		Hue        uint16
+		Saturation uint16
+		Brightness uint16
+		Kelvin     uint16
+	}
+
+	// State is the state of the device that its messages read and change.
+	State struct {
+		Color  HSBK
+		Power  uint16
+		Label  string
+		Uptime uint64
+		Lux    float32
+		// White makes the device drop the hue and saturation of colors it's sent, like white-only products.
+		White bool
+
+		Vendor, Product uint32
+		// Firmware is the host firmware version, major in the high and minor in the low 16 bits.
+		Firmware uint32
+	}
+
+	// Light is a fake device that answers on a loopback UDP port.
+	Light struct {
+		Mac uint64
+
+		conn *net.UDPConn
+
+		mu       sync.Mutex
+		state    State
+		drop     map[uint16]int
+		replies  map[uint16]reply
+		received []uint16
+		// client is the address the last message came from.
+		client *net.UDPAddr
+	}
+
+	reply struct {
+		t       uint16
+		payload []byte
+	}
+)
+
+// New starts a device with the MAC address on a free loopback port.
+func New(mac uint64) (*Light, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Light{
+		Mac:     mac,
+		conn:    conn,
+		drop:    make(map[uint16]int),
+		replies: make(map[uint16]reply),
+	}
+
+	go o.serve()
+
+	return o, nil
+}
+
+// Addr returns the address the device answers on.
+func (o *Light) Addr() *net.UDPAddr {
+	return o.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Close stops the device.
+func (o *Light) Close() error {
+	return o.conn.Close()
+}
+
+// State returns the state of the device.
+func (o *Light) State() State {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.state
+}
+
+// Update changes the state of the device.
+func (o *Light) Update(f func(s *State)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	f(&o.state)
+}
+
+// Drop ignores the next n messages of the type, or all of them if n is negative.
+func (o *Light) Drop(t uint16, n int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.drop[t] = n
+}
+
+// Reply answers messages of the type with the payload in a message of the reply type, instead of how the device
+// would otherwise answer.
+func (o *Light) Reply(t, replyType uint16, payload []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.replies[t] = reply{t: replyType, payload: payload}
+}
+
+// Received returns the types of all messages the device received, including dropped ones, in order.
+func (o *Light) Received() []uint16 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]uint16(nil), o.received...)
+}
+
+// Count returns the number of messages of the type the device received, including dropped ones.
+func (o *Light) Count(t uint16) (n int) {
+	for _, r := range o.Received() {
+		if r == t {
+			n++
+		}
+	}
+
+	return
+}
+
+// Announce sends the state of the device unsolicited to the client that last sent it a message, like devices
+// broadcast their state when they're changed by another client. It does nothing if no message was received yet.
+func (o *Light) Announce() {
+	o.mu.Lock()
+	client, p := o.client, lightState(&o.state)
+	o.mu.Unlock()
+
+	if client != nil {
+		o.send(client, make([]byte, headerSize), lightStateType, p)
+	}
+}
+
+func (o *Light) serve() {
+	b := make([]byte, 1024)
+
+	for {
+		n, raddr, err := o.conn.ReadFromUDP(b)
+		if err != nil {
+			return
+		}
+		if n < headerSize {
+			continue
+		}
+
+		header := append([]byte(nil), b[:headerSize]...)
+		payload := append([]byte(nil), b[headerSize:n]...)
+
+		if t, p, ok := o.handle(raddr, header, payload); ok {
+			o.send(raddr, header, t, p)
+		}
+	}
+}
+
+// handle changes the state by the message from the client at the address and returns the answer, if any.
+func (o *Light) handle(raddr *net.UDPAddr, header, payload []byte) (t uint16, p []byte, ok bool) {
+	le := binary.LittleEndian
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	req := le.Uint16(header[32:])
+	o.received = append(o.received, req)
+	o.client = raddr
+
+	if n, drop := o.drop[req]; drop && n != 0 {
+		if n > 0 {
+			o.drop[req] = n - 1
+		}
+
+		return 0, nil, false
+	}
+
+	if r, ok := o.replies[req]; ok {
+		return r.t, r.payload, true
+	}
+
+	s := &o.state
+
+	switch req {
+	case lightGetType:
+		return lightStateType, lightState(s), true
+	case getPowerType, lightGetPowerType:
+		p = make([]byte, 2)
+		le.PutUint16(p, s.Power)
+		if req == getPowerType {
+			return statePowerType, p, true
+		}
+		return lightStatePowerType, p, true
+	case getLabelType:
+		p = make([]byte, 32)
+		copy(p, s.Label)
+		return stateLabelType, p, true
+	case getVersionType:
+		p = make([]byte, 12)
+		le.PutUint32(p, s.Vendor)
+		le.PutUint32(p[4:], s.Product)
+		return stateVersionType, p, true
+	case getHostFirmwareType:
+		// The build time and reserved bytes are zero, then the minor and major version follow.
+		p = make([]byte, 20)
+		le.PutUint16(p[16:], uint16(s.Firmware))
+		le.PutUint16(p[18:], uint16(s.Firmware>>16))
+		return stateHostFirmwareType, p, true
+	case getInfoType:
+		p = make([]byte, 24)
+		le.PutUint64(p[8:], s.Uptime)
+		return stateInfoType, p, true
+	case sensorGetAmbientLightType:
+		p = make([]byte, 4)
+		le.PutUint32(p, math.Float32bits(s.Lux))
+		return sensorStateAmbientType, p, true
+	case lightSetColorType:
+		if len(payload) >= 9 {
+			s.Color = HSBK{le.Uint16(payload[1:]), le.Uint16(payload[3:]), le.Uint16(payload[5:]),
+				le.Uint16(payload[7:])}
+			if s.White {
+				s.Color.Hue, s.Color.Saturation = 0, 0
+			}
+		}
+	case setPowerType, lightSetPowerType:
+		if len(payload) >= 2 {
+			s.Power = le.Uint16(payload)
+		}
+	case lightSetDimAbsoluteType:
+		if len(payload) >= 2 {
+			s.Color.Brightness = le.Uint16(payload)
+		}
+	case lightSetDimRelativeType:
+		if len(payload) >= 4 {
+			b := int64(s.Color.Brightness) + int64(int32(le.Uint32(payload)))
+			if b < 0 {
+				b = 0
+			} else if b > 0xffff {
+				b = 0xffff
+			}
+			s.Color.Brightness = uint16(b)
+		}
+	}
+
+	// Anything else is a set message, which is acknowledged if asked to.
+	if header[22]&0x02 == 0 {
+		return 0, nil, false
+	}
+
+	return acknowledgementType, nil, true
+}
+
+func (o *Light) send(addr *net.UDPAddr, header []byte, t uint16, payload []byte) {
+	le := binary.LittleEndian
+
+	b := append(header, payload...)
+	le.PutUint16(b, uint16(len(b)))
+	// Not tagged; addressable, protocol 1024.
+	b[3] = 0x14
+	le.PutUint64(b[8:], o.Mac)
+	le.PutUint16(b[32:], t)
+
+	o.conn.WriteToUDP(b, addr)
+}
+
+func lightState(s *State) []byte {
+	p := make([]byte, 52)
+	putColor(p, s.Color)
+	binary.LittleEndian.PutUint16(p[10:], s.Power)
+	copy(p[12:44], s.Label)
+
+	return p
+}
+
+func putColor(b []byte, c HSBK) {
+	le := binary.LittleEndian
+
+	le.PutUint16(b, c.Hue)
+	le.PutUint16(b[2:], c.Saturation)
+	le.PutUint16(b[4:], c.Brightness)
+	le.PutUint16(b[6:], c.Kelvin)
+}
@@ -0,0 +1,211 @@
+package controlifx
+
+import "math"
+
+// Interpolation is the color space in which colors are blended.
+type Interpolation int
+
+const (
+	// HSBKInterpolation blends the components of the colors, taking the shorter way around the hue circle.
+	HSBKInterpolation Interpolation = iota
+	// LinearRGBInterpolation blends the colors in linear light, like mixing the light of two bulbs.
+	LinearRGBInterpolation
+	// OklabInterpolation blends the colors in the perceptually uniform Oklab color space, which avoids muddy and
+	// overly dark midpoints.
+	OklabInterpolation
+)
+
+const (
+	// hueTolerance, saturationTolerance and brightnessTolerance are how far the hue, saturation and brightness that a
+	// device reports may be off the color it was sent, about 1% of their range, since devices round colors.
+	hueTolerance        = 0x10000 / 100
+	saturationTolerance = 0x10000 / 100
+	brightnessTolerance = 0x10000 / 100
+	// kelvinTolerance is how far the color temperature that a device reports may be off the one it was sent.
+	kelvinTolerance = 50
+)
+
+// Interpolate returns the color at t in the range [0, 1] between a and b. The color temperature is always blended
+// linearly.
+func Interpolate(a, b HSBK, t float64, space Interpolation) HSBK {
+	switch {
+	case t <= 0:
+		return a
+	case t >= 1:
+		return b
+	}
+
+	kelvin := uint16(math.Round(lerp(float64(a.Kelvin), float64(b.Kelvin), t)))
+
+	switch space {
+	case LinearRGBInterpolation, OklabInterpolation:
+		ar, ag, ab := a.rgb()
+		br, bg, bb := b.rgb()
+
+		to, from := srgbToLinear, linearToSrgb
+		if space == OklabInterpolation {
+			to, from = srgbToOklab, oklabToSrgb
+		}
+
+		x1, y1, z1 := to(ar, ag, ab)
+		x2, y2, z2 := to(br, bg, bb)
+
+		r, g, bl := from(lerp(x1, x2, t), lerp(y1, y2, t), lerp(z1, z2, t))
+		h, s, v := rgbToHsv(clamp01(r), clamp01(g), clamp01(bl))
+
+		c := HSBK{
+			Hue:        quantizeHue(h),
+			Saturation: quantize(s),
+			Brightness: quantize(v),
+			Kelvin:     kelvin,
+		}
+
+		// Keep the hue of grays, which have none of their own.
+		if c.Saturation == 0 {
+			c.Hue = Interpolate(a, b, t, HSBKInterpolation).Hue
+		}
+
+		return c
+	}
+
+	// The hue of a color without saturation is meaningless, so don't sweep through the hue circle when fading from
+	// or to white.
+	aHue, bHue := float64(a.Hue), float64(b.Hue)
+	switch {
+	case a.Saturation == 0 && b.Saturation != 0:
+		aHue = bHue
+	case b.Saturation == 0 && a.Saturation != 0:
+		bHue = aHue
+	}
+
+	d := bHue - aHue
+	switch {
+	case d > 0x8000:
+		d -= 0x10000
+	case d < -0x8000:
+		d += 0x10000
+	}
+
+	return HSBK{
+		Hue:        uint16(int(math.Round(aHue+d*t)) & 0xffff),
+		Saturation: uint16(math.Round(lerp(float64(a.Saturation), float64(b.Saturation), t))),
+		Brightness: uint16(math.Round(lerp(float64(a.Brightness), float64(b.Brightness), t))),
+		Kelvin:     kelvin,
+	}
+}
+
+// Gradient returns n colors evenly spaced along the stops, starting with the first and ending with the last stop.
+// This can be used for the zones of multizone devices or the steps of a multi-step transition.
+func Gradient(stops []HSBK, n int, space Interpolation) []HSBK {
+	if n <= 0 || len(stops) == 0 {
+		return nil
+	}
+
+	colors := make([]HSBK, n)
+
+	if len(stops) == 1 || n == 1 {
+		for i := range colors {
+			colors[i] = stops[0]
+		}
+		return colors
+	}
+
+	segments := float64(len(stops) - 1)
+	for i := range colors {
+		pos := float64(i) / float64(n-1) * segments
+
+		seg := int(pos)
+		if seg >= len(stops)-1 {
+			seg = len(stops) - 2
+		}
+
+		colors[i] = Interpolate(stops[seg], stops[seg+1], pos-float64(seg), space)
+	}
+
+	return colors
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// Between returns whether the color is on a transition from a to b, give or take the rounding of devices, so that a
+// color read back from a device can be checked against what it was sent. The hue only matters for saturated colors,
+// and must be on the shorter arc between the hues. A color is between a and a if it's a, give or take the rounding.
+func (o HSBK) Between(a, b HSBK) bool {
+	if !within(o.Saturation, a.Saturation, b.Saturation, saturationTolerance) ||
+		!within(o.Brightness, a.Brightness, b.Brightness, brightnessTolerance) ||
+		!within(o.Kelvin, a.Kelvin, b.Kelvin, kelvinTolerance) {
+		return false
+	}
+
+	if o.Saturation < saturationTolerance {
+		return true
+	}
+
+	return hueDistance(a.Hue, o.Hue)+hueDistance(o.Hue, b.Hue) <= hueDistance(a.Hue, b.Hue)+hueTolerance
+}
+
+// within returns whether v is between a and b, give or take the tolerance.
+func within(v, a, b uint16, tolerance int) bool {
+	if a > b {
+		a, b = b, a
+	}
+
+	return int(v) >= int(a)-tolerance && int(v) <= int(b)+tolerance
+}
+
+// hueDistance returns the distance between two hues along the shorter arc.
+func hueDistance(a, b uint16) int {
+	d := int(a - b)
+	if d > 0x8000 {
+		d = 0x10000 - d
+	}
+
+	return d
+}
+
+func srgbToLinear(r, g, b float64) (float64, float64, float64) {
+	return srgbLinear(r), srgbLinear(g), srgbLinear(b)
+}
+
+func linearToSrgb(r, g, b float64) (float64, float64, float64) {
+	return srgbGamma(clamp01(r)), srgbGamma(clamp01(g)), srgbGamma(clamp01(b))
+}
+
+// srgbToOklab converts gamma-encoded sRGB components in the range [0, 1] to Oklab.
+func srgbToOklab(r, g, b float64) (l, a, bb float64) {
+	r, g, b = srgbToLinear(r, g, b)
+
+	lms := [3]float64{
+		math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b),
+		math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b),
+		math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b),
+	}
+
+	return 0.2104542553*lms[0] + 0.7936177850*lms[1] - 0.0040720468*lms[2],
+		1.9779984951*lms[0] - 2.4285922050*lms[1] + 0.4505937099*lms[2],
+		0.0259040371*lms[0] + 0.7827717662*lms[1] - 0.8086757660*lms[2]
+}
+
+// oklabToSrgb converts Oklab to gamma-encoded sRGB components, clipped to the range [0, 1].
+func oklabToSrgb(l, a, b float64) (float64, float64, float64) {
+	lms := [3]float64{
+		l + 0.3963377774*a + 0.2158037573*b,
+		l - 0.1055613458*a - 0.0638541728*b,
+		l - 0.0894841775*a - 1.2914855480*b,
+	}
+	for i, v := range lms {
+		lms[i] = v * v * v
+	}
+
+	return linearToSrgb(
+		4.0767416621*lms[0]-3.3077115913*lms[1]+0.2309699292*lms[2],
+		-1.2684380046*lms[0]+2.6097574011*lms[1]-0.3413193965*lms[2],
+		-0.0041960863*lms[0]-0.7034186147*lms[1]+1.7076147010*lms[2],
+	)
+}
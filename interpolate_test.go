@@ -0,0 +1,139 @@
+package controlifx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	a := HSBKFromHSV(350, 100, 100)
+	b := HSBKFromHSV(10, 100, 100)
+
+	// The shorter way around the hue circle passes through red.
+	if expected, c := (HSBK{0, 0xffff, 0xffff, DefaultKelvin}), Interpolate(a, b, 0.5, HSBKInterpolation); expected != c {
+		t.Errorf("expected '%#v', got '%#v'", expected, c)
+	}
+
+	if c := Interpolate(b, a, 0.25, HSBKInterpolation); c.Hue != quantizeHue(5) {
+		t.Errorf("expected hue '%d', got '%d'", quantizeHue(5), c.Hue)
+	}
+}
+
+func TestInterpolate2(t *testing.T) {
+	white := HSBK{0, 0, 0xffff, 2500}
+	blue := HSBK{43691, 0xffff, 0x8000, 9000}
+
+	expected := HSBK{43691, 0x8000, 0xc000, 5750}
+	if c := Interpolate(white, blue, 0.5, HSBKInterpolation); expected != c {
+		t.Errorf("expected '%#v', got '%#v'", expected, c)
+	}
+}
+
+func TestInterpolate3(t *testing.T) {
+	red := RGB{0xff, 0, 0}.HSBK()
+	blue := RGB{0, 0, 0xff}.HSBK()
+
+	for _, space := range []Interpolation{HSBKInterpolation, LinearRGBInterpolation, OklabInterpolation} {
+		if c := Interpolate(red, blue, 0, space); red != c {
+			t.Errorf("%d: expected '%#v', got '%#v'", space, red, c)
+		}
+
+		if c := Interpolate(red, blue, 1, space); blue != c {
+			t.Errorf("%d: expected '%#v', got '%#v'", space, blue, c)
+		}
+	}
+
+	if expected, c := (RGB{0xbc, 0x00, 0xbc}), Interpolate(red, blue, 0.5, LinearRGBInterpolation).RGB(); expected != c {
+		t.Errorf("expected '%s', got '%s'", expected.Hex(), c.Hex())
+	}
+
+	// Same as color-mix(in oklab, red, blue) in CSS.
+	if expected, c := (RGB{0x8c, 0x53, 0xa2}), Interpolate(red, blue, 0.5, OklabInterpolation).RGB(); expected != c {
+		t.Errorf("expected '%s', got '%s'", expected.Hex(), c.Hex())
+	}
+}
+
+func TestSrgbToOklab(t *testing.T) {
+	tests := []struct {
+		r, g, b  float64
+		expected [3]float64
+	}{
+		{1, 1, 1, [3]float64{1, 0, 0}},
+		{1, 0, 0, [3]float64{0.62796, 0.22486, 0.12585}},
+		{0, 0, 1, [3]float64{0.45201, -0.03246, -0.31153}},
+	}
+
+	for _, test := range tests {
+		l, a, b := srgbToOklab(test.r, test.g, test.b)
+		if math.Abs(test.expected[0]-l) > 1e-4 || math.Abs(test.expected[1]-a) > 1e-4 || math.Abs(test.expected[2]-b) > 1e-4 {
+			t.Errorf("expected '%v', got '%v %v %v'", test.expected, l, a, b)
+		}
+
+		r, g, bl := oklabToSrgb(l, a, b)
+		if math.Abs(test.r-r) > 1e-6 || math.Abs(test.g-g) > 1e-6 || math.Abs(test.b-bl) > 1e-6 {
+			t.Errorf("round trip: expected '%v %v %v', got '%v %v %v'", test.r, test.g, test.b, r, g, bl)
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	stops := []HSBK{
+		{0, 0xffff, 0xffff, 3500},
+		{0x4000, 0xffff, 0xffff, 3500},
+		{0x8000, 0xffff, 0xffff, 3500},
+	}
+
+	colors := Gradient(stops, 5, HSBKInterpolation)
+
+	expected := []uint16{0, 0x2000, 0x4000, 0x6000, 0x8000}
+	if len(expected) != len(colors) {
+		t.Fatalf("expected %d colors, got %d", len(expected), len(colors))
+	}
+
+	for i, c := range colors {
+		if expected[i] != c.Hue {
+			t.Errorf("%d: expected hue '%#x', got '%#x'", i, expected[i], c.Hue)
+		}
+	}
+
+	if colors := Gradient(stops[:1], 3, OklabInterpolation); len(colors) != 3 || colors[2] != stops[0] {
+		t.Errorf("unexpected gradient '%#v'", colors)
+	}
+
+	if colors := Gradient(stops, 0, HSBKInterpolation); colors != nil {
+		t.Errorf("expected no colors, got '%#v'", colors)
+	}
+}
+
+func TestHSBK_Between(t *testing.T) {
+	a := HSBK{Hue: 0xf000, Saturation: 0xffff, Brightness: 0x1000, Kelvin: 2500}
+	b := HSBK{Hue: 0x1000, Saturation: 0xffff, Brightness: 0x2000, Kelvin: 3500}
+
+	tests := []struct {
+		c        HSBK
+		expected bool
+	}{
+		{a, true},
+		{b, true},
+		// The hue crosses 0 on the shorter arc.
+		{HSBK{Hue: 0x0100, Saturation: 0xffff, Brightness: 0x1800, Kelvin: 3000}, true},
+		// Rounded by the device.
+		{HSBK{Hue: 0x1080, Saturation: 0xff00, Brightness: 0x2080, Kelvin: 3540}, true},
+		{HSBK{Hue: 0x8000, Saturation: 0xffff, Brightness: 0x1800, Kelvin: 3000}, false},
+		{HSBK{Hue: 0x1000, Saturation: 0xffff, Brightness: 0x4000, Kelvin: 3000}, false},
+		{HSBK{Hue: 0x1000, Saturation: 0xffff, Brightness: 0x1800, Kelvin: 4000}, false},
+		{HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0x1800, Kelvin: 3000}, false},
+	}
+
+	for _, test := range tests {
+		if v := test.c.Between(a, b); v != test.expected {
+			t.Errorf("%+v: expected '%v', got '%v'", test.c, test.expected, v)
+		}
+	}
+
+	// The hue of whites doesn't matter.
+	white := HSBK{Hue: 0x1000, Brightness: 0x8000, Kelvin: 2700}
+	if c := (HSBK{Hue: 0x9000, Brightness: 0x8000, Kelvin: 2700}); !c.Between(white, white) {
+		t.Errorf("%+v: expected 'true', got 'false'", c)
+	}
+}
@@ -0,0 +1,128 @@
+package controlifx
+
+import "math"
+
+// Range of color temperatures covered by KelvinToRGB.
+const (
+	MinModelKelvin = 1000
+	MaxModelKelvin = 40000
+)
+
+// KelvinToMired converts a color temperature to micro reciprocal degrees.
+func KelvinToMired(kelvin uint16) float64 {
+	if kelvin == 0 {
+		return math.Inf(1)
+	}
+
+	return 1e6 / float64(kelvin)
+}
+
+// MiredToKelvin converts micro reciprocal degrees to a color temperature, clamped to the range of uint16.
+func MiredToKelvin(mired float64) uint16 {
+	if mired <= 1e6/math.MaxUint16 {
+		return math.MaxUint16
+	}
+
+	return uint16(math.Round(1e6 / mired))
+}
+
+// KelvinToRGB approximates the color of a black body at the temperature, normalized so that the brightest channel
+// is full. Temperatures are clamped to the range of MinModelKelvin and MaxModelKelvin.
+func KelvinToRGB(kelvin uint16) RGB {
+	r, g, b := kelvinRgb(kelvin)
+
+	return RGB{
+		R: uint8(math.Round(r * 0xff)),
+		G: uint8(math.Round(g * 0xff)),
+		B: uint8(math.Round(b * 0xff)),
+	}
+}
+
+// RGBToKelvin returns the color temperature whose black body color is closest to the color, ignoring its
+// brightness. The result is in the range of MinModelKelvin and MaxModelKelvin.
+func RGBToKelvin(c RGB) uint16 {
+	max := math.Max(float64(c.R), math.Max(float64(c.G), float64(c.B)))
+	if max == 0 {
+		return MinModelKelvin
+	}
+
+	r, g, b := float64(c.R)/max, float64(c.G)/max, float64(c.B)/max
+
+	dist := func(kelvin uint16) float64 {
+		kr, kg, kb := kelvinRgb(kelvin)
+
+		return (kr-r)*(kr-r) + (kg-g)*(kg-g) + (kb-b)*(kb-b)
+	}
+
+	// Coarse search followed by a fine search around the best match.
+	best := uint16(MinModelKelvin)
+	for k := uint16(MinModelKelvin); k <= MaxModelKelvin; k += 50 {
+		if dist(k) < dist(best) {
+			best = k
+		}
+	}
+
+	lo, hi := best-50, best+50
+	if best == MinModelKelvin {
+		lo = MinModelKelvin
+	}
+	for k := lo; k <= hi && k <= MaxModelKelvin; k++ {
+		if dist(k) < dist(best) {
+			best = k
+		}
+	}
+
+	return best
+}
+
+// kelvinRgb returns the gamma-encoded RGB components in the range [0, 1] of a black body at the temperature, using
+// Tanner Helland's fit of the CIE 1964 color matching functions.
+func kelvinRgb(kelvin uint16) (r, g, b float64) {
+	t := math.Max(MinModelKelvin, math.Min(MaxModelKelvin, float64(kelvin))) / 100
+
+	if t <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(t) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(t-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(t-60, -0.0755148492)
+	}
+
+	switch {
+	case t >= 66:
+		b = 255
+	case t <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(t-10) - 305.0447927307
+	}
+
+	clamp := func(v float64) float64 {
+		return math.Max(0, math.Min(255, v)) / 255
+	}
+
+	return clamp(r), clamp(g), clamp(b)
+}
+
+// RenderRGB returns the color a device shows for the HSBK. Unlike RGB, it takes the color temperature into account:
+// the white point of the kelvin is mixed with the hue in linear light according to the saturation, so fully
+// desaturated colors are the white of the color temperature and fully saturated colors are the pure hue.
+func (o HSBK) RenderRGB() RGB {
+	wr, wg, wb := kelvinRgb(o.Kelvin)
+	hr, hg, hb := hsvToRgb(float64(o.Hue)/0x10000*360, 1, 1)
+
+	s := float64(o.Saturation) / 0xffff
+	v := float64(o.Brightness) / 0xffff
+
+	mix := func(w, h float64) uint8 {
+		lin := (1-s)*srgbLinear(w) + s*srgbLinear(h)
+
+		return uint8(math.Round(srgbGamma(lin) * v * 0xff))
+	}
+
+	return RGB{
+		R: mix(wr, hr),
+		G: mix(wg, hg),
+		B: mix(wb, hb),
+	}
+}
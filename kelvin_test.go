@@ -0,0 +1,67 @@
+package controlifx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKelvinToMired(t *testing.T) {
+	if m := KelvinToMired(2700); math.Abs(370.37-m) > 0.01 {
+		t.Errorf("expected '370.37', got '%.2f'", m)
+	}
+
+	if k := MiredToKelvin(370.37); k != 2700 {
+		t.Errorf("expected '2700', got '%d'", k)
+	}
+
+	if k := MiredToKelvin(0); k != math.MaxUint16 {
+		t.Errorf("expected '%d', got '%d'", math.MaxUint16, k)
+	}
+}
+
+func TestKelvinToRGB(t *testing.T) {
+	tests := map[uint16]RGB{
+		1000:  {0xff, 0x44, 0x00},
+		2700:  {0xff, 0xa7, 0x57},
+		6600:  {0xff, 0xff, 0xff},
+		9000:  {0xd2, 0xdf, 0xff},
+		500:   {0xff, 0x44, 0x00},
+		50000: {0x98, 0xba, 0xff},
+	}
+
+	for k, expected := range tests {
+		if c := KelvinToRGB(k); expected != c {
+			t.Errorf("%dK: expected '%s', got '%s'", k, expected.Hex(), c.Hex())
+		}
+	}
+}
+
+func TestRGBToKelvin(t *testing.T) {
+	for k := uint16(1500); k <= 9000; k += 250 {
+		c := KelvinToRGB(k)
+
+		if v := RGBToKelvin(c); math.Abs(float64(v)-float64(k))/float64(k) > 0.02 {
+			t.Errorf("%s: expected '%d' within 2%%, got '%d'", c.Hex(), k, v)
+		}
+	}
+}
+
+func TestHSBK_RenderRGB(t *testing.T) {
+	tests := []struct {
+		o        HSBK
+		expected RGB
+	}{
+		{HSBK{0, 0xffff, 0xffff, 2700}, RGB{0xff, 0x00, 0x00}},
+		{HSBK{21845, 0xffff, 0x8080, 9000}, RGB{0x00, 0x80, 0x00}},
+		{HSBK{0, 0, 0xffff, 6600}, RGB{0xff, 0xff, 0xff}},
+		{HSBK{0, 0, 0xffff, 2700}, KelvinToRGB(2700)},
+		{HSBK{0, 0, 0, 2700}, RGB{0x00, 0x00, 0x00}},
+		{HSBK{43691, 0x8000, 0xffff, 6600}, RGB{0xbc, 0xbc, 0xff}},
+	}
+
+	for _, test := range tests {
+		if c := test.o.RenderRGB(); test.expected != c {
+			t.Errorf("%#v: expected '%s', got '%s'", test.o, test.expected.Hex(), c.Hex())
+		}
+	}
+}
@@ -381,6 +381,17 @@ const (
 	TriangleWaveform = 3
 	PulseWaveform    = 4
 
+	OffPowerLevel = 0
+	OnPowerLevel  = 0xffff
+
+	// MinKelvin and MaxKelvin are the range of color temperatures that devices accept. Individual products may
+	// support a narrower range.
+	MinKelvin = 1500
+	MaxKelvin = 9000
+
+	// MaxLabelSize is the size of labels in bytes.
+	MaxLabelSize = 32
+
 	OffWanStatus                    = 0
 	ConnectedWanStatus              = 1
 	ErrorUnauthorizedWanStatus      = 2
@@ -410,7 +421,10 @@ const (
 	Wpa2AesPskWifiSecurity   = 5
 	Wpa2TkipPskWifiSecurity  = 6
 	Wpa2MixedPskWifiSecurity = 7
+)
 
+// Deprecated: Use LookupProduct, which covers all products in LIFX's product registry.
+const (
 	Original1000VendorId     = 1
 	Color650VendorId         = 1
 	White800LowVVendorId     = 1
@@ -472,6 +486,8 @@ func getReceivablePayloadOfType(t uint16) (encoding.BinaryUnmarshaler, error) {
 		payload = &LightStateLanMessage{}
 	case LightStatePowerType:
 		payload = &LightStatePowerLanMessage{}
+	case SensorStateAmbientLightType:
+		payload = &SensorStateAmbientLightLanMessage{}
 	default:
 		return nil, fmt.Errorf("cannot create new payload of type %d; is it binary decodable?", t)
 	}
@@ -541,15 +557,17 @@ func GetHostFirmware() SendableLanMessage {
 
 type StateHostFirmwareLanMessage struct {
 	Build   uint64
-	Version uint32
+	Version FirmwareVersion
 }
 
 func (o *StateHostFirmwareLanMessage) UnmarshalBinary(data []byte) error {
 	// Build.
 	o.Build = binary.LittleEndian.Uint64(data[:8])
 
-	// Version.
-	o.Version = binary.LittleEndian.Uint32(data[8:])
+	// Reserved.
+
+	// Version, minor before major.
+	o.Version = NewFirmwareVersion(binary.LittleEndian.Uint16(data[18:20]), binary.LittleEndian.Uint16(data[16:18]))
 
 	return nil
 }
@@ -583,15 +601,17 @@ func GetWifiFirmware() SendableLanMessage {
 
 type StateWifiFirmwareLanMessage struct {
 	Build   uint64
-	Version uint32
+	Version FirmwareVersion
 }
 
 func (o *StateWifiFirmwareLanMessage) UnmarshalBinary(data []byte) error {
 	// Build.
 	o.Build = binary.LittleEndian.Uint64(data[:8])
 
-	// Version.
-	o.Version = binary.LittleEndian.Uint32(data[8:])
+	// Reserved.
+
+	// Version, minor before major.
+	o.Version = NewFirmwareVersion(binary.LittleEndian.Uint16(data[18:20]), binary.LittleEndian.Uint16(data[16:18]))
 
 	return nil
 }
@@ -925,6 +945,158 @@ func LightSetColor(payload LightSetColorLanMessage) SendableLanMessage {
 	return msg
 }
 
+type LightSetWaveformLanMessage struct {
+	Transient bool
+	Color     HSBK
+	Period    uint32
+	Cycles    float32
+	SkewRatio int16
+	Waveform  uint8
+}
+
+func (o LightSetWaveformLanMessage) MarshalBinary() (data []byte, err error) {
+	data = make([]byte, 21)
+
+	// Transient.
+	if o.Transient {
+		data[1] = 1
+	}
+
+	// Color.
+	color, err := o.Color.MarshalBinary()
+	if err != nil {
+		return
+	}
+	copy(data[2:10], color)
+
+	// Period.
+	binary.LittleEndian.PutUint32(data[10:14], o.Period)
+
+	// Cycles.
+	binary.LittleEndian.PutUint32(data[14:18], math.Float32bits(o.Cycles))
+
+	// Skew ratio.
+	binary.LittleEndian.PutUint16(data[18:20], uint16(o.SkewRatio))
+
+	// Waveform.
+	data[20] = o.Waveform
+
+	return
+}
+
+func LightSetWaveform(payload LightSetWaveformLanMessage) SendableLanMessage {
+	msg := createSendableLanMessage(LightSetWaveformType)
+	msg.Payload = payload
+
+	msg.updateSize()
+
+	return msg
+}
+
+type LightSetWaveformOptionalLanMessage struct {
+	Transient     bool
+	Color         HSBK
+	Period        uint32
+	Cycles        float32
+	SkewRatio     int16
+	Waveform      uint8
+	SetHue        bool
+	SetSaturation bool
+	SetBrightness bool
+	SetKelvin     bool
+}
+
+func (o LightSetWaveformOptionalLanMessage) MarshalBinary() (data []byte, err error) {
+	waveform, err := LightSetWaveformLanMessage{
+		Transient: o.Transient,
+		Color:     o.Color,
+		Period:    o.Period,
+		Cycles:    o.Cycles,
+		SkewRatio: o.SkewRatio,
+		Waveform:  o.Waveform,
+	}.MarshalBinary()
+	if err != nil {
+		return
+	}
+
+	data = make([]byte, 25)
+	copy(data, waveform)
+
+	// Set hue, saturation, brightness and kelvin.
+	for i, set := range []bool{o.SetHue, o.SetSaturation, o.SetBrightness, o.SetKelvin} {
+		if set {
+			data[21+i] = 1
+		}
+	}
+
+	return
+}
+
+func LightSetWaveformOptional(payload LightSetWaveformOptionalLanMessage) SendableLanMessage {
+	msg := createSendableLanMessage(LightSetWaveformOptionalType)
+	msg.Payload = payload
+
+	msg.updateSize()
+
+	return msg
+}
+
+// LightSetDimAbsoluteLanMessage sets the brightness of a light without changing the rest of its color. It's only
+// supported by older firmware; AdjustColor uses it where it's acknowledged and works on all devices.
+type LightSetDimAbsoluteLanMessage struct {
+	Brightness uint16
+	Duration   uint32
+}
+
+func (o LightSetDimAbsoluteLanMessage) MarshalBinary() (data []byte, _ error) {
+	data = make([]byte, 6)
+
+	// Brightness.
+	binary.LittleEndian.PutUint16(data[:2], o.Brightness)
+
+	// Duration.
+	binary.LittleEndian.PutUint32(data[2:], o.Duration)
+
+	return
+}
+
+func LightSetDimAbsolute(payload LightSetDimAbsoluteLanMessage) SendableLanMessage {
+	msg := createSendableLanMessage(LightSetDimAbsoluteType)
+	msg.Payload = payload
+
+	msg.updateSize()
+
+	return msg
+}
+
+// LightSetDimRelativeLanMessage changes the brightness of a light by a signed amount in the range [-65535, 65535]
+// without changing the rest of its color. It's only supported by older firmware; AdjustColor works on all devices.
+type LightSetDimRelativeLanMessage struct {
+	Brightness int32
+	Duration   uint32
+}
+
+func (o LightSetDimRelativeLanMessage) MarshalBinary() (data []byte, _ error) {
+	data = make([]byte, 8)
+
+	// Brightness.
+	binary.LittleEndian.PutUint32(data[:4], uint32(o.Brightness))
+
+	// Duration.
+	binary.LittleEndian.PutUint32(data[4:], o.Duration)
+
+	return
+}
+
+func LightSetDimRelative(payload LightSetDimRelativeLanMessage) SendableLanMessage {
+	msg := createSendableLanMessage(LightSetDimRelativeType)
+	msg.Payload = payload
+
+	msg.updateSize()
+
+	return msg
+}
+
 type LightStateLanMessage struct {
 	Color HSBK
 	Power uint16
@@ -988,6 +1160,22 @@ func (o *LightStatePowerLanMessage) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+func SensorGetAmbientLight() SendableLanMessage {
+	return createSendableLanMessage(SensorGetAmbientLightType)
+}
+
+type SensorStateAmbientLightLanMessage struct {
+	// Lux is the illuminance measured by the device.
+	Lux float32
+}
+
+func (o *SensorStateAmbientLightLanMessage) UnmarshalBinary(data []byte) error {
+	// Lux.
+	o.Lux = math.Float32frombits(binary.LittleEndian.Uint32(data[:4]))
+
+	return nil
+}
+
 func BToStr(b []byte) string {
 	return string(bytes.TrimRight(b, "\x00"))
 }
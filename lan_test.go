@@ -1,5 +1,10 @@
 package controlifx
 
+import (
+	"bytes"
+	"testing"
+)
+
 /*
 import (
 	"bytes"
@@ -1621,3 +1626,122 @@ func TestLightStatePowerLanMessage_UnmarshalBinary(t *testing.T) {
 	}
 }
 */
+
+func TestLightSetWaveformLanMessage_MarshalBinary(t *testing.T) {
+	o := LightSetWaveformLanMessage{
+		Transient: true,
+		Color: HSBK{
+			Hue:        0x1fff,
+			Saturation: 0x2fff,
+			Brightness: 0x3fff,
+			Kelvin:     0x0dac,
+		},
+		Period:    1000,
+		Cycles:    2.5,
+		SkewRatio: -0x8000,
+		Waveform:  PulseWaveform,
+	}
+
+	b, err := o.MarshalBinary()
+	if err != nil {
+		t.Error("error:", err)
+	}
+
+	expected := []byte{0x0, 0x1, 0xff, 0x1f, 0xff, 0x2f, 0xff, 0x3f, 0xac, 0x0d, 0xe8, 0x03, 0x0, 0x0, 0x0, 0x0,
+		0x20, 0x40, 0x0, 0x80, 0x4}
+
+	if !bytes.Equal(expected, b) {
+		t.Errorf("expected '%#v', got '%#v'", expected, b)
+	}
+}
+
+func TestLightSetWaveformOptionalLanMessage_MarshalBinary(t *testing.T) {
+	o := LightSetWaveformOptionalLanMessage{
+		Color: HSBK{
+			Brightness: 0xffff,
+		},
+		Period:        500,
+		Cycles:        1,
+		Waveform:      SineWaveform,
+		SetBrightness: true,
+		SetKelvin:     true,
+	}
+
+	b, err := o.MarshalBinary()
+	if err != nil {
+		t.Error("error:", err)
+	}
+
+	expected := []byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0xff, 0xff, 0x0, 0x0, 0xf4, 0x01, 0x0, 0x0, 0x0, 0x0,
+		0x80, 0x3f, 0x0, 0x0, 0x1, 0x0, 0x0, 0x1, 0x1}
+
+	if !bytes.Equal(expected, b) {
+		t.Errorf("expected '%#v', got '%#v'", expected, b)
+	}
+
+	if msg := LightSetWaveformOptional(o); msg.Header.Frame.Size != LanHeaderSize+25 {
+		t.Errorf("expected size '%d', got '%d'", LanHeaderSize+25, msg.Header.Frame.Size)
+	}
+}
+
+func TestLightSetDimAbsoluteLanMessage_MarshalBinary(t *testing.T) {
+	o := LightSetDimAbsoluteLanMessage{
+		Brightness: 0x8000,
+		Duration:   1000,
+	}
+
+	b, err := o.MarshalBinary()
+	if err != nil {
+		t.Error("error:", err)
+	}
+
+	expected := []byte{0x0, 0x80, 0xe8, 0x03, 0x0, 0x0}
+
+	if !bytes.Equal(expected, b) {
+		t.Errorf("expected '%#v', got '%#v'", expected, b)
+	}
+
+	if msg := LightSetDimAbsolute(o); msg.Header.Frame.Size != LanHeaderSize+6 {
+		t.Errorf("expected size '%d', got '%d'", LanHeaderSize+6, msg.Header.Frame.Size)
+	}
+}
+
+func TestLightSetDimRelativeLanMessage_MarshalBinary(t *testing.T) {
+	o := LightSetDimRelativeLanMessage{
+		Brightness: -0x1000,
+		Duration:   500,
+	}
+
+	b, err := o.MarshalBinary()
+	if err != nil {
+		t.Error("error:", err)
+	}
+
+	expected := []byte{0x0, 0xf0, 0xff, 0xff, 0xf4, 0x01, 0x0, 0x0}
+
+	if !bytes.Equal(expected, b) {
+		t.Errorf("expected '%#v', got '%#v'", expected, b)
+	}
+
+	if msg := LightSetDimRelative(o); msg.Header.Frame.Size != LanHeaderSize+8 {
+		t.Errorf("expected size '%d', got '%d'", LanHeaderSize+8, msg.Header.Frame.Size)
+	}
+}
+
+func TestSensorStateAmbientLightLanMessage_UnmarshalBinary(t *testing.T) {
+	o := SensorStateAmbientLightLanMessage{}
+
+	b := []byte{0x0, 0x0, 0x48, 0x43}
+
+	if err := o.UnmarshalBinary(b); err != nil {
+		t.Error("error:", err)
+	}
+
+	expected := SensorStateAmbientLightLanMessage{
+		Lux: 200,
+	}
+
+	if expected != o {
+		t.Errorf("expected '%#v', got '%#v'", expected, o)
+	}
+}
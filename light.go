@@ -0,0 +1,176 @@
+package controlifx
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Light is a single LIFX device reached through a connection. Each method sends one message to the device and waits
+// for its response or acknowledgement, resending the message as described by SendToAndGetContext.
+type Light struct {
+	Conn   Connection
+	Device Device
+}
+
+func NewLight(conn Connection, device Device) Light {
+	return Light{
+		Conn:   conn,
+		Device: device,
+	}
+}
+
+// Get sends the message to the light and returns the first response that has the payload type.
+func (o Light) Get(ctx context.Context, msg SendableLanMessage, t uint16) (recMsg ReceivableLanMessage, err error) {
+	recMsgs, err := o.Conn.SendToAndGetContext(ctx, msg, []Device{o.Device}, TypeFilter(t))
+	if err != nil {
+		return
+	}
+
+	recMsg, ok := recMsgs[o.Device]
+	if !ok {
+		err = ErrNoResponse
+	}
+
+	return
+}
+
+// Set sends the message to the light and waits for the acknowledgement.
+func (o Light) Set(ctx context.Context, msg SendableLanMessage) error {
+	msg.Header.FrameAddress.AckRequired = true
+
+	_, err := o.Get(ctx, msg, AcknowledgementType)
+
+	return err
+}
+
+func (o Light) Label(ctx context.Context) (string, error) {
+	recMsg, err := o.Get(ctx, GetLabel(), StateLabelType)
+	if err != nil {
+		return "", err
+	}
+
+	return recMsg.Payload.(*StateLabelLanMessage).Label, nil
+}
+
+func (o Light) SetLabel(ctx context.Context, label string) error {
+	return o.Set(ctx, SetLabel(SetLabelLanMessage{
+		Label: label,
+	}))
+}
+
+// Power returns whether the light is on.
+func (o Light) Power(ctx context.Context) (bool, error) {
+	recMsg, err := o.Get(ctx, LightGetPower(), LightStatePowerType)
+	if err != nil {
+		return false, err
+	}
+
+	return recMsg.Payload.(*LightStatePowerLanMessage).Level != OffPowerLevel, nil
+}
+
+// SetPower turns the light on or off over the duration.
+func (o Light) SetPower(ctx context.Context, on bool, duration time.Duration) error {
+	var level uint16 = OffPowerLevel
+	if on {
+		level = OnPowerLevel
+	}
+
+	return o.Set(ctx, LightSetPower(LightSetPowerLanMessage{
+		Level:    level,
+		Duration: DurationToMs(duration),
+	}))
+}
+
+// State returns the color, power level and label of the light.
+func (o Light) State(ctx context.Context) (LightStateLanMessage, error) {
+	recMsg, err := o.Get(ctx, LightGet(), LightStateType)
+	if err != nil {
+		return LightStateLanMessage{}, err
+	}
+
+	return *recMsg.Payload.(*LightStateLanMessage), nil
+}
+
+func (o Light) Color(ctx context.Context) (HSBK, error) {
+	state, err := o.State(ctx)
+
+	return state.Color, err
+}
+
+// SetColor transitions the light to the color over the duration.
+func (o Light) SetColor(ctx context.Context, color HSBK, duration time.Duration) error {
+	return o.Set(ctx, LightSetColor(LightSetColorLanMessage{
+		Color:    color,
+		Duration: DurationToMs(duration),
+	}))
+}
+
+func (o Light) Version(ctx context.Context) (StateVersionLanMessage, error) {
+	recMsg, err := o.Get(ctx, GetVersion(), StateVersionType)
+	if err != nil {
+		return StateVersionLanMessage{}, err
+	}
+
+	return *recMsg.Payload.(*StateVersionLanMessage), nil
+}
+
+func (o Light) HostFirmware(ctx context.Context) (StateHostFirmwareLanMessage, error) {
+	recMsg, err := o.Get(ctx, GetHostFirmware(), StateHostFirmwareType)
+	if err != nil {
+		return StateHostFirmwareLanMessage{}, err
+	}
+
+	return *recMsg.Payload.(*StateHostFirmwareLanMessage), nil
+}
+
+func (o Light) WifiInfo(ctx context.Context) (StateWifiInfoLanMessage, error) {
+	recMsg, err := o.Get(ctx, GetWifiInfo(), StateWifiInfoType)
+	if err != nil {
+		return StateWifiInfoLanMessage{}, err
+	}
+
+	return *recMsg.Payload.(*StateWifiInfoLanMessage), nil
+}
+
+func (o Light) Group(ctx context.Context) (StateGroupLanMessage, error) {
+	recMsg, err := o.Get(ctx, GetGroup(), StateGroupType)
+	if err != nil {
+		return StateGroupLanMessage{}, err
+	}
+
+	return *recMsg.Payload.(*StateGroupLanMessage), nil
+}
+
+func (o Light) Location(ctx context.Context) (StateLocationLanMessage, error) {
+	recMsg, err := o.Get(ctx, GetLocation(), StateLocationType)
+	if err != nil {
+		return StateLocationLanMessage{}, err
+	}
+
+	return *recMsg.Payload.(*StateLocationLanMessage), nil
+}
+
+// AmbientLight returns the illuminance in lux measured by the light's sensor. Only some products have one.
+func (o Light) AmbientLight(ctx context.Context) (float32, error) {
+	recMsg, err := o.Get(ctx, SensorGetAmbientLight(), SensorStateAmbientLightType)
+	if err != nil {
+		return 0, err
+	}
+
+	return recMsg.Payload.(*SensorStateAmbientLightLanMessage).Lux, nil
+}
+
+// DurationToMs converts the duration to the number of milliseconds used by transition durations in messages.
+func DurationToMs(d time.Duration) uint32 {
+	ms := d / time.Millisecond
+
+	switch {
+	case ms < 0:
+		return 0
+	case ms > math.MaxUint32:
+		return math.MaxUint32
+	}
+
+	return uint32(ms)
+}
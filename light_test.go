@@ -0,0 +1,72 @@
+package controlifx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestDurationToMs(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected uint32
+	}{
+		{0, 0},
+		{-time.Second, 0},
+		{time.Millisecond - 1, 0},
+		{1500 * time.Millisecond, 1500},
+		{time.Hour, 3600000},
+		{math.MaxInt64, math.MaxUint32},
+	}
+
+	for _, test := range tests {
+		if v := DurationToMs(test.d); v != test.expected {
+			t.Errorf("%v: expected '%d', got '%d'", test.d, test.expected, v)
+		}
+	}
+}
+
+func TestLight(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	f, d := testLight(t, 1)
+	defer f.Close()
+
+	light := NewLight(conn, d)
+	ctx := context.Background()
+
+	c := HSBK{Hue: 0x1000, Saturation: 0x2000, Brightness: 0x3000, Kelvin: 3500}
+	if err := light.SetColor(ctx, c, time.Second); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := light.SetPower(ctx, true, 0); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	state, err := light.State(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if state.Color != c {
+		t.Errorf("expected '%v', got '%v'", c, state.Color)
+	}
+	if on, err := light.Power(ctx); err != nil || !on {
+		t.Errorf("expected 'true', got '%v' (%v)", on, err)
+	}
+
+	f.Update(func(s *fakelight.State) { s.Lux = 250.5 })
+	if lux, err := light.AmbientLight(ctx); err != nil || lux != 250.5 {
+		t.Errorf("expected '250.5', got '%v' (%v)", lux, err)
+	}
+
+	// Set waits for the acknowledgement.
+	f.Drop(LightSetPowerType, -1)
+	if err := light.SetPower(ctx, false, 0); !errors.Is(err, ErrNoResponse) {
+		t.Errorf("expected '%v', got '%v'", ErrNoResponse, err)
+	}
+}
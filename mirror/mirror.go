@@ -0,0 +1,343 @@
+// Package mirror makes LIFX lights follow another light: whenever the power or color of the leader changes, the
+// followers are changed the same way.
+package mirror
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/clock"
+)
+
+const (
+	// DefaultPollInterval is the interval between polls of the leader of mirrors whose options don't specify one.
+	DefaultPollInterval = 2 * time.Second
+
+	// listenTimeout is the most milliseconds the mirror holds the connection while listening for broadcasts, so that
+	// others sharing the connection aren't blocked for long.
+	listenTimeout = controlifx.NormalTimeout
+)
+
+type (
+	// Follower is a device that follows the leader.
+	Follower struct {
+		Device controlifx.Device
+		// Scale multiplies the brightness of the leader, so that followers can be dimmer or brighter. The brightness
+		// is capped at full. It's 1 if 0.
+		Scale float64
+	}
+
+	// Options configure a mirror.
+	Options struct {
+		// PollInterval is the interval between polls of the leader, which catch changes that the leader didn't
+		// broadcast.
+		PollInterval time.Duration
+		// Duration is the duration of the transitions of the followers.
+		Duration time.Duration
+	}
+
+	// Status is the state of a follower as last set by the mirror.
+	Status struct {
+		Follower Follower
+		// Color and Power are what was last sent to the follower.
+		Color controlifx.HSBK
+		Power bool
+		// LastApplied is the time the follower last acknowledged a change.
+		LastApplied time.Time
+		// Err is the error of the last change, if it failed. The change is tried again at the next poll.
+		Err error
+
+		// applied is set while Color and Power were acknowledged by the follower.
+		applied bool
+	}
+
+	// Mirror replicates the power and color of a leader to followers.
+	Mirror struct {
+		conn controlifx.Connection
+		opts Options
+
+		mu        sync.Mutex
+		leader    controlifx.Device
+		state     controlifx.LightStateLanMessage
+		lastSeen  time.Time
+		followers map[controlifx.MAC]*Status
+	}
+)
+
+// New returns a mirror of the leader without followers.
+func New(conn controlifx.Connection, leader controlifx.Device, opts Options) *Mirror {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	return &Mirror{
+		conn:      conn,
+		opts:      opts,
+		leader:    leader,
+		followers: make(map[controlifx.MAC]*Status),
+	}
+}
+
+// Add adds the follower, or replaces the follower with the same device. It's changed to the leader's state at the
+// next poll or change of the leader.
+func (o *Mirror) Add(follower Follower) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.followers[follower.Device.Mac] = &Status{Follower: follower}
+}
+
+// Remove removes the follower.
+func (o *Mirror) Remove(mac controlifx.MAC) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.followers, mac)
+}
+
+// Leader returns the last seen state of the leader and when it was seen. The time is zero if the leader wasn't seen
+// yet.
+func (o *Mirror) Leader() (state controlifx.LightStateLanMessage, lastSeen time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.state, o.lastSeen
+}
+
+// Status returns the status of all followers, ordered by MAC address.
+func (o *Mirror) Status() []Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	statuses := make([]Status, 0, len(o.followers))
+	for _, s := range o.followers {
+		statuses = append(statuses, *s)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Follower.Device.Mac < statuses[j].Follower.Device.Mac
+	})
+
+	return statuses
+}
+
+// Run mirrors the leader until the context is done, and returns the context's error. Between polls every
+// PollInterval, it listens for the states that the leader broadcasts when it's changed by another client, so that
+// the followers change with little delay.
+func (o *Mirror) Run(ctx context.Context) error {
+	var next time.Time
+
+	for {
+		if now := time.Now(); !now.Before(next) {
+			// A leader that doesn't respond is polled again next time.
+			o.Poll(ctx)
+			next = now.Add(o.opts.PollInterval)
+		}
+
+		timeout := listenTimeout
+		if ms := int(time.Until(next) / time.Millisecond); ms < timeout {
+			timeout = ms
+		}
+		if timeout < 1 {
+			continue
+		}
+
+		recMsg, d, ok, err := o.conn.Listen(ctx, timeout, o.fromLeader)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			// The connection failed; don't spin until the next poll.
+			if err := clock.Sleep(ctx, time.Until(next)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		o.mu.Lock()
+		// Follow the leader to its new address.
+		o.leader.Addr = d.Addr
+		state, seen := o.state, !o.lastSeen.IsZero()
+		o.mu.Unlock()
+
+		switch payload := recMsg.Payload.(type) {
+		case *controlifx.LightStateLanMessage:
+			state, seen = *payload, true
+		case *controlifx.LightStatePowerLanMessage:
+			state.Power = payload.Level
+		case *controlifx.StatePowerLanMessage:
+			state.Power = payload.Level
+		}
+
+		if !seen {
+			// Only the power is known; poll the rest.
+			next = time.Time{}
+			continue
+		}
+
+		o.Update(ctx, state)
+	}
+}
+
+// Poll reads the state of the leader and updates the followers with it.
+func (o *Mirror) Poll(ctx context.Context) error {
+	o.mu.Lock()
+	leader := o.leader
+	o.mu.Unlock()
+
+	recMsg, err := controlifx.NewLight(o.conn, leader).Get(ctx, controlifx.LightGet(), controlifx.LightStateType)
+	if err != nil {
+		return err
+	}
+
+	o.Update(ctx, *recMsg.Payload.(*controlifx.LightStateLanMessage))
+
+	return nil
+}
+
+// Update records the state as the leader's and sends the followers whose color or power differ from it the changes,
+// waiting for their acknowledgements. The colors are changed before the power, so that followers that are turned on
+// come on in the leader's color.
+func (o *Mirror) Update(ctx context.Context, state controlifx.LightStateLanMessage) {
+	o.mu.Lock()
+	o.state, o.lastSeen = state, time.Now()
+	colors, powers := plan(state, o.followers)
+	o.mu.Unlock()
+
+	for c, devices := range colors {
+		errs := o.send(ctx, controlifx.LightSetColor(controlifx.LightSetColorLanMessage{
+			Color:    c,
+			Duration: controlifx.DurationToMs(o.opts.Duration),
+		}), devices)
+
+		o.record(devices, errs, func(s *Status) {
+			s.Color = c
+		})
+	}
+
+	for on, devices := range powers {
+		var level uint16 = controlifx.OffPowerLevel
+		if on {
+			level = controlifx.OnPowerLevel
+		}
+
+		errs := o.send(ctx, controlifx.LightSetPower(controlifx.LightSetPowerLanMessage{
+			Level:    level,
+			Duration: controlifx.DurationToMs(o.opts.Duration),
+		}), devices)
+
+		o.record(devices, errs, func(s *Status) {
+			s.Power = on
+		})
+	}
+}
+
+// fromLeader filters out messages that aren't a state of the leader.
+func (o *Mirror) fromLeader(recMsg controlifx.ReceivableLanMessage) bool {
+	o.mu.Lock()
+	leader := o.leader.Mac
+	o.mu.Unlock()
+
+	switch recMsg.Header.ProtocolHeader.Type {
+	case controlifx.LightStateType, controlifx.LightStatePowerType, controlifx.StatePowerType:
+		return controlifx.MAC(recMsg.Header.FrameAddress.Target) == leader
+	}
+
+	return false
+}
+
+// send sends the message to the devices and returns the error of each device, which is nil for those that
+// acknowledged it.
+func (o *Mirror) send(ctx context.Context, msg controlifx.SendableLanMessage, devices []controlifx.Device) map[controlifx.MAC]error {
+	msg.Header.FrameAddress.AckRequired = true
+
+	errs := make(map[controlifx.MAC]error, len(devices))
+
+	recMsgs, err := o.conn.SendToAndGetContext(ctx, msg, devices, controlifx.TypeFilter(controlifx.AcknowledgementType))
+	if err == nil {
+		err = controlifx.ErrNoResponse
+	}
+
+	for _, d := range devices {
+		if _, ok := recMsgs[d]; ok {
+			errs[d.Mac] = nil
+		} else {
+			errs[d.Mac] = err
+		}
+	}
+
+	return errs
+}
+
+// record updates the statuses of the devices with their errors, changing those that acknowledged.
+func (o *Mirror) record(devices []controlifx.Device, errs map[controlifx.MAC]error, change func(s *Status)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+
+	for _, d := range devices {
+		s, ok := o.followers[d.Mac]
+		if !ok {
+			continue
+		}
+
+		if s.Err = errs[d.Mac]; s.Err != nil {
+			s.applied = false
+			continue
+		}
+
+		change(s)
+		s.LastApplied = now
+	}
+}
+
+// plan groups the followers that need to be changed to follow the state by their new color and power. Followers
+// that failed to acknowledge a change are changed again.
+func plan(state controlifx.LightStateLanMessage, followers map[controlifx.MAC]*Status) (colors map[controlifx.HSBK][]controlifx.Device, powers map[bool][]controlifx.Device) {
+	colors = make(map[controlifx.HSBK][]controlifx.Device)
+	powers = make(map[bool][]controlifx.Device)
+
+	on := state.Power != controlifx.OffPowerLevel
+
+	for _, s := range followers {
+		d := s.Follower.Device
+
+		c := s.Follower.scale(state.Color)
+		if !s.applied || s.Color != c {
+			colors[c] = append(colors[c], d)
+		}
+		if !s.applied || s.Power != on {
+			powers[on] = append(powers[on], d)
+		}
+
+		// Until a change fails, the follower counts as changed.
+		s.applied = true
+	}
+
+	return
+}
+
+// scale returns the color with the brightness scaled for the follower.
+func (o Follower) scale(c controlifx.HSBK) controlifx.HSBK {
+	if o.Scale == 0 || o.Scale == 1 {
+		return c
+	}
+
+	b := math.Round(float64(c.Brightness) * o.Scale)
+	if b > 0xffff {
+		b = 0xffff
+	} else if b < 0 {
+		b = 0
+	}
+	c.Brightness = uint16(b)
+
+	return c
+}
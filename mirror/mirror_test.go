@@ -0,0 +1,226 @@
+package mirror
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestFollowerScale(t *testing.T) {
+	c := controlifx.HSBK{Hue: 100, Saturation: 200, Brightness: 0x8000, Kelvin: 3500}
+
+	tests := []struct {
+		scale    float64
+		expected uint16
+	}{
+		{0, 0x8000},
+		{1, 0x8000},
+		{0.5, 0x4000},
+		{2, 0xffff},
+		{-1, 0},
+	}
+
+	for _, test := range tests {
+		v := Follower{Scale: test.scale}.scale(c)
+		if v.Brightness != test.expected {
+			t.Errorf("scale %v: expected brightness '%#x', got '%#x'", test.scale, test.expected, v.Brightness)
+		}
+		if v.Hue != c.Hue || v.Saturation != c.Saturation || v.Kelvin != c.Kelvin {
+			t.Errorf("scale %v: expected only the brightness to change, got '%+v'", test.scale, v)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	a := controlifx.Device{Mac: 1}
+	b := controlifx.Device{Mac: 2}
+	c := controlifx.Device{Mac: 3}
+
+	state := controlifx.LightStateLanMessage{
+		Color: controlifx.HSBK{Brightness: 0x8000, Kelvin: 3500},
+		Power: controlifx.OnPowerLevel,
+	}
+	full := state.Color
+	half := full
+	half.Brightness = 0x4000
+
+	followers := map[controlifx.MAC]*Status{
+		// Never changed.
+		a.Mac: {Follower: Follower{Device: a}},
+		// In sync.
+		b.Mac: {Follower: Follower{Device: b, Scale: 0.5}, Color: half, Power: true, applied: true},
+		// Only the power differs.
+		c.Mac: {Follower: Follower{Device: c}, Color: full, applied: true},
+	}
+
+	colors, powers := plan(state, followers)
+
+	if len(colors) != 1 || len(colors[full]) != 1 || colors[full][0] != a {
+		t.Errorf("expected the color of only '%v' to change, got '%v'", a, colors)
+	}
+	if len(powers) != 1 || len(powers[true]) != 2 {
+		t.Errorf("expected '%v' and '%v' to be turned on, got '%v'", a, c, powers)
+	}
+
+	for mac, s := range followers {
+		if !s.applied {
+			t.Errorf("%v: expected to count as changed", mac)
+		}
+	}
+
+	// Followers whose change failed are changed again.
+	followers[a.Mac].Color, followers[a.Mac].Power = full, true
+	followers[c.Mac].Power = true
+	followers[b.Mac].applied = false
+	colors, powers = plan(state, followers)
+	if len(colors) != 1 || len(colors[half]) != 1 || len(powers) != 1 || len(powers[true]) != 1 {
+		t.Errorf("expected '%v' to be changed again, got '%v' and '%v'", b, colors, powers)
+	}
+}
+
+// testLight returns a fake light and its Device. It must be closed.
+func testLight(t *testing.T, mac uint64, color fakelight.HSBK, power uint16) (*fakelight.Light, controlifx.Device) {
+	f, err := fakelight.New(mac)
+	if err != nil {
+		t.Fatal("cannot start fake device:", err)
+	}
+
+	f.Update(func(s *fakelight.State) {
+		s.Color, s.Power = color, power
+	})
+
+	return f, controlifx.Device{Addr: f.Addr(), Mac: controlifx.MAC(mac)}
+}
+
+// waitFor waits until the light has the color and power, and fails the test if it doesn't within a second.
+func waitFor(t *testing.T, f *fakelight.Light, color fakelight.HSBK, power uint16) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if s := f.State(); s.Color == color && s.Power == power {
+			return
+		}
+	}
+
+	s := f.State()
+	t.Fatalf("%x: expected '%v' at power '%#x', got '%v' at power '%#x'", f.Mac, color, power, s.Color, s.Power)
+}
+
+func TestMirror_Run(t *testing.T) {
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	conn, err := controlifx.ConnectFrom(loopback, loopback)
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+	defer conn.Close()
+
+	red := fakelight.HSBK{Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500}
+	white := fakelight.HSBK{Brightness: 0xffff, Kelvin: 3500}
+
+	leader, dl := testLight(t, 1, red, controlifx.OnPowerLevel)
+	defer leader.Close()
+	a, da := testLight(t, 2, white, controlifx.OffPowerLevel)
+	defer a.Close()
+	b, db := testLight(t, 3, white, controlifx.OffPowerLevel)
+	defer b.Close()
+
+	// Only the first poll, then the mirror relies on the leader's broadcasts.
+	m := New(conn, dl, Options{PollInterval: time.Hour})
+	m.Add(Follower{Device: da})
+	m.Add(Follower{Device: db, Scale: 0.5})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	halfRed := red
+	halfRed.Brightness = 0x4000
+
+	waitFor(t, a, red, controlifx.OnPowerLevel)
+	waitFor(t, b, halfRed, controlifx.OnPowerLevel)
+
+	// The colors are changed before the power, so that followers come on in the leader's color.
+	for _, f := range []*fakelight.Light{a, b} {
+		r := f.Received()
+		if len(r) != 2 || r[0] != controlifx.LightSetColorType || r[1] != controlifx.LightSetPowerType {
+			t.Errorf("%x: expected the color and then the power, got '%v'", f.Mac, r)
+		}
+	}
+
+	// The leader is changed by another client and broadcasts its state.
+	leader.Update(func(s *fakelight.State) { s.Color = white })
+	leader.Announce()
+
+	halfWhite := white
+	halfWhite.Brightness = 0x8000
+
+	waitFor(t, a, white, controlifx.OnPowerLevel)
+	waitFor(t, b, halfWhite, controlifx.OnPowerLevel)
+
+	// The leader comes back at another address and is followed there.
+	moved, _ := testLight(t, 1, white, controlifx.OffPowerLevel)
+	defer moved.Close()
+	// The fake only broadcasts to clients it heard from.
+	if err := controlifx.NewLight(conn, controlifx.Device{Addr: moved.Addr(), Mac: 1}).SetLabel(ctx, "moved"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	moved.Announce()
+
+	waitFor(t, a, white, controlifx.OffPowerLevel)
+	waitFor(t, b, halfWhite, controlifx.OffPowerLevel)
+
+	if err := m.Poll(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if n := moved.Count(controlifx.LightGetType); n != 1 {
+		t.Errorf("expected '1' poll at the new address, got '%d'", n)
+	}
+}
+
+func TestMirror_Run_broadcastFirst(t *testing.T) {
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	conn, err := controlifx.ConnectFrom(loopback, loopback)
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+	defer conn.Close()
+
+	red := fakelight.HSBK{Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500}
+
+	leader, dl := testLight(t, 1, red, controlifx.OnPowerLevel)
+	defer leader.Close()
+	a, da := testLight(t, 2, fakelight.HSBK{}, controlifx.OffPowerLevel)
+	defer a.Close()
+
+	// The leader never answers polls, so the mirror can only follow its broadcasts.
+	leader.Drop(controlifx.LightGetType, -1)
+
+	m := New(conn, dl, Options{PollInterval: time.Hour})
+	m.Add(Follower{Device: da})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Wait until the first poll has given up.
+	for leader.Count(controlifx.LightGetType) <= controlifx.DefaultRetries {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(controlifx.NormalTimeout * time.Millisecond)
+
+	leader.Announce()
+
+	waitFor(t, a, red, controlifx.OnPowerLevel)
+}
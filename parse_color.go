@@ -0,0 +1,188 @@
+package controlifx
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PartialHSBK is a change of some of the components of a color. Only the components whose Set flag is true are
+// meant to be changed, like in LightSetWaveformOptional.
+type PartialHSBK struct {
+	Color         HSBK
+	SetHue        bool
+	SetSaturation bool
+	SetBrightness bool
+	SetKelvin     bool
+}
+
+// Apply returns the color with the set components changed.
+func (o PartialHSBK) Apply(c HSBK) HSBK {
+	if o.SetHue {
+		c.Hue = o.Color.Hue
+	}
+	if o.SetSaturation {
+		c.Saturation = o.Color.Saturation
+	}
+	if o.SetBrightness {
+		c.Brightness = o.Color.Brightness
+	}
+	if o.SetKelvin {
+		c.Kelvin = o.Color.Kelvin
+	}
+
+	return c
+}
+
+// WaveformOptional returns a LightSetWaveformOptional payload that changes the set components.
+func (o PartialHSBK) WaveformOptional(waveform LightSetWaveformLanMessage) LightSetWaveformOptionalLanMessage {
+	return LightSetWaveformOptionalLanMessage{
+		Transient:     waveform.Transient,
+		Color:         o.Color,
+		Period:        waveform.Period,
+		Cycles:        waveform.Cycles,
+		SkewRatio:     waveform.SkewRatio,
+		Waveform:      waveform.Waveform,
+		SetHue:        o.SetHue,
+		SetSaturation: o.SetSaturation,
+		SetBrightness: o.SetBrightness,
+		SetKelvin:     o.SetKelvin,
+	}
+}
+
+// namedColors are the hues of the named colors of the LIFX HTTP API.
+var namedColors = map[string]float64{
+	"red":    0,
+	"orange": 36,
+	"yellow": 60,
+	"green":  120,
+	"cyan":   180,
+	"blue":   250,
+	"purple": 280,
+	"pink":   325,
+}
+
+// ParseColor parses a color in the syntax of the LIFX HTTP API. The color is a space separated list of the
+// following, where later entries override earlier ones:
+//
+//	white, red, orange, yellow, cyan, green, blue, purple, pink
+//	hue:[0-360] saturation:[0.0-1.0] brightness:[0.0-1.0]
+//	kelvin:[1500-9000]   also sets the saturation to 0
+//	rgb:[0-255],[0-255],[0-255]
+//	#RRGGBB
+//
+// Other CSS color names are accepted as well. Colors in the RGB forms set the hue, saturation and brightness.
+func ParseColor(s string) (o PartialHSBK, err error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		err = fmt.Errorf("invalid color %q: empty", s)
+		return
+	}
+
+	for _, f := range fields {
+		if err = o.parseField(f); err != nil {
+			err = fmt.Errorf("invalid color %q: %v", s, err)
+			return
+		}
+	}
+
+	return
+}
+
+func (o *PartialHSBK) parseField(f string) error {
+	if f == "white" {
+		o.Color.Saturation, o.SetSaturation = 0, true
+		return nil
+	}
+
+	if hue, ok := namedColors[f]; ok {
+		o.Color.Hue, o.SetHue = quantizeHue(hue), true
+		o.Color.Saturation, o.SetSaturation = 0xffff, true
+		return nil
+	}
+
+	if strings.HasPrefix(f, "#") {
+		c, err := ParseHex(f)
+		if err != nil {
+			return err
+		}
+
+		o.setRGB(c)
+		return nil
+	}
+
+	i := strings.IndexByte(f, ':')
+	if i < 0 {
+		c, ok := LookupCSSColor(f)
+		if !ok {
+			return fmt.Errorf("unknown color %q", f)
+		}
+
+		o.setRGB(c)
+		return nil
+	}
+
+	key, value := f[:i], f[i+1:]
+
+	if key == "rgb" {
+		parts := strings.Split(value, ",")
+		if len(parts) != 3 {
+			return fmt.Errorf("rgb needs 3 components, got %d", len(parts))
+		}
+
+		var c [3]uint8
+		for j, p := range parts {
+			v, err := strconv.ParseUint(p, 10, 8)
+			if err != nil {
+				return fmt.Errorf("invalid rgb component %q", p)
+			}
+			c[j] = uint8(v)
+		}
+
+		o.setRGB(RGB{c[0], c[1], c[2]})
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	// ParseFloat accepts "nan" and "inf", and NaN would pass the range checks.
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("invalid %s %q", key, value)
+	}
+
+	switch key {
+	case "hue":
+		if v < 0 || v > 360 {
+			return fmt.Errorf("hue %v out of range [0, 360]", v)
+		}
+		o.Color.Hue, o.SetHue = quantizeHue(v), true
+	case "saturation":
+		if v < 0 || v > 1 {
+			return fmt.Errorf("saturation %v out of range [0, 1]", v)
+		}
+		o.Color.Saturation, o.SetSaturation = quantize(v), true
+	case "brightness":
+		if v < 0 || v > 1 {
+			return fmt.Errorf("brightness %v out of range [0, 1]", v)
+		}
+		o.Color.Brightness, o.SetBrightness = quantize(v), true
+	case "kelvin":
+		if v < MinKelvin || v > MaxKelvin {
+			return fmt.Errorf("kelvin %v out of range [%d, %d]", v, MinKelvin, MaxKelvin)
+		}
+		o.Color.Kelvin, o.SetKelvin = uint16(v), true
+		o.Color.Saturation, o.SetSaturation = 0, true
+	default:
+		return fmt.Errorf("unknown component %q", key)
+	}
+
+	return nil
+}
+
+func (o *PartialHSBK) setRGB(c RGB) {
+	hsbk := c.HSBK()
+
+	o.Color.Hue, o.SetHue = hsbk.Hue, true
+	o.Color.Saturation, o.SetSaturation = hsbk.Saturation, true
+	o.Color.Brightness, o.SetBrightness = hsbk.Brightness, true
+}
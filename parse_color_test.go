@@ -0,0 +1,93 @@
+package controlifx
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]PartialHSBK{
+		"red": {
+			Color:  HSBK{Hue: 0, Saturation: 0xffff},
+			SetHue: true, SetSaturation: true,
+		},
+		"white": {
+			SetSaturation: true,
+		},
+		"hue:120 saturation:1.0": {
+			Color:  HSBK{Hue: 21845, Saturation: 0xffff},
+			SetHue: true, SetSaturation: true,
+		},
+		"kelvin:2700 brightness:0.5": {
+			Color:         HSBK{Brightness: 0x8000, Kelvin: 2700},
+			SetSaturation: true, SetBrightness: true, SetKelvin: true,
+		},
+		"rgb:255,0,0": {
+			Color:  HSBK{Hue: 0, Saturation: 0xffff, Brightness: 0xffff},
+			SetHue: true, SetSaturation: true, SetBrightness: true,
+		},
+		"#0000FF": {
+			Color:  HSBK{Hue: 43691, Saturation: 0xffff, Brightness: 0xffff},
+			SetHue: true, SetSaturation: true, SetBrightness: true,
+		},
+		"  Blue   saturation:0.5 ": {
+			Color:  HSBK{Hue: 45511, Saturation: 0x8000},
+			SetHue: true, SetSaturation: true,
+		},
+		"kelvin:3500 saturation:0.25": {
+			Color:         HSBK{Saturation: 0x4000, Kelvin: 3500},
+			SetSaturation: true, SetKelvin: true,
+		},
+		"teal": {
+			Color:  HSBK{Hue: 32768, Saturation: 0xffff, Brightness: 0x8080},
+			SetHue: true, SetSaturation: true, SetBrightness: true,
+		},
+	}
+
+	for s, expected := range tests {
+		o, err := ParseColor(s)
+		if err != nil {
+			t.Errorf("%q: error: %v", s, err)
+			continue
+		}
+
+		if expected != o {
+			t.Errorf("%q: expected '%#v', got '%#v'", s, expected, o)
+		}
+	}
+}
+
+func TestParseColor2(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"notacolor",
+		"hue:361",
+		"hue:abc",
+		"hue:nan",
+		"saturation:NaN",
+		"brightness:inf",
+		"kelvin:nan",
+		"kelvin:+Inf",
+		"saturation:1.5",
+		"brightness:-0.1",
+		"kelvin:1000",
+		"rgb:256,0,0",
+		"rgb:255,0",
+		"#ff00",
+		"foo:1",
+	} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestPartialHSBK_Apply(t *testing.T) {
+	o := PartialHSBK{
+		Color:         HSBK{Hue: 1, Saturation: 2, Brightness: 3, Kelvin: 4},
+		SetBrightness: true,
+		SetKelvin:     true,
+	}
+
+	expected := HSBK{Hue: 10, Saturation: 20, Brightness: 3, Kelvin: 4}
+	if c := o.Apply(HSBK{10, 20, 30, 40}); expected != c {
+		t.Errorf("expected '%#v', got '%#v'", expected, c)
+	}
+}
@@ -0,0 +1,298 @@
+// Package presence makes a home look occupied while nobody is there by turning the lights of rooms on and off in
+// randomized patterns during the evening.
+package presence
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/clock"
+	"github.com/yath/controlifx/scene"
+)
+
+const (
+	// DefaultStart and DefaultEnd are the evening of simulators whose options don't specify one, as times since
+	// midnight.
+	DefaultStart = 18 * time.Hour
+	DefaultEnd   = 23*time.Hour + 30*time.Minute
+
+	// DefaultJitter is the jitter of simulators whose options don't specify one.
+	DefaultJitter = 30 * time.Minute
+
+	// DefaultTransition is the duration of the power transitions of simulators whose options don't specify one.
+	DefaultTransition = 2 * time.Second
+
+	// DefaultMinOn, DefaultMaxOn, DefaultMinOff and DefaultMaxOff are the durations of rooms that don't specify
+	// them.
+	DefaultMinOn  = 10 * time.Minute
+	DefaultMaxOn  = time.Hour
+	DefaultMinOff = 5 * time.Minute
+	DefaultMaxOff = 45 * time.Minute
+
+	day = 24 * time.Hour
+)
+
+type (
+	// Room is a group of lights that are turned on and off together, like someone entering and leaving the room.
+	Room struct {
+		// Group is the label of the group of the lights.
+		Group string
+		// MinOn and MaxOn bound how long the lights stay on. They're DefaultMinOn and DefaultMaxOn if 0, but the
+		// default minimum is no longer than a maximum that is set.
+		MinOn, MaxOn time.Duration
+		// MinOff and MaxOff bound how long the lights stay off between visits, with defaults like MinOn and MaxOn.
+		MinOff, MaxOff time.Duration
+	}
+
+	// Options configure a simulator.
+	Options struct {
+		Rooms []Room
+
+		// Start and End are the evening as times since midnight in the local time zone. End may be past midnight,
+		// as in 25*time.Hour, or before Start.
+		Start, End time.Duration
+		// Jitter is the most that the start and end of each evening are moved at random, and the most by which the
+		// rooms are turned off after the end. It's DefaultJitter if 0, and no jitter is added if it's negative.
+		Jitter time.Duration
+		// Transition is the duration of the power transitions.
+		Transition time.Duration
+
+		// Logger logs what the simulator does. It logs to standard error if nil.
+		Logger *log.Logger
+		// Rand is the source of randomness. It's seeded with the time if nil.
+		Rand *rand.Rand
+	}
+
+	// Action turns the lights of a room on or off.
+	Action struct {
+		Time time.Time
+		Room string
+		On   bool
+	}
+
+	// Simulator turns the lights of rooms on and off each evening.
+	Simulator struct {
+		conn controlifx.Connection
+		opts Options
+	}
+)
+
+// New returns a simulator for the rooms.
+func New(conn controlifx.Connection, opts Options) *Simulator {
+	if opts.Start == 0 && opts.End == 0 {
+		opts.Start, opts.End = DefaultStart, DefaultEnd
+	}
+	if opts.End <= opts.Start {
+		opts.End += day
+	}
+	if opts.Jitter < 0 {
+		opts.Jitter = 0
+	} else if opts.Jitter == 0 {
+		opts.Jitter = DefaultJitter
+	}
+	if opts.Transition <= 0 {
+		opts.Transition = DefaultTransition
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.New(os.Stderr, "presence: ", log.LstdFlags)
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	for i := range opts.Rooms {
+		r := &opts.Rooms[i]
+		r.MinOn, r.MaxOn = bounds(r.MinOn, r.MaxOn, DefaultMinOn, DefaultMaxOn)
+		r.MinOff, r.MaxOff = bounds(r.MinOff, r.MaxOff, DefaultMinOff, DefaultMaxOff)
+	}
+
+	return &Simulator{
+		conn: conn,
+		opts: opts,
+	}
+}
+
+// Plan returns the actions of the evening that starts on the date of the day, ordered by time. Each room is visited
+// at random times throughout the evening and turned off at the end.
+func (o *Simulator) Plan(date time.Time) (actions []Action) {
+	year, month, d := date.Date()
+	midnight := time.Date(year, month, d, 0, 0, 0, 0, date.Location())
+
+	start := midnight.Add(o.opts.Start + o.jitter())
+	end := midnight.Add(o.opts.End + o.jitter())
+
+	for _, r := range o.opts.Rooms {
+		// Rooms don't all light up at the start.
+		t := start.Add(o.between(0, r.MaxOff))
+
+		for t.Before(end) {
+			off := t.Add(o.between(r.MinOn, r.MaxOn))
+			if off.After(end) {
+				off = end.Add(o.between(0, o.opts.Jitter))
+			}
+
+			actions = append(actions, Action{Time: t, Room: r.Group, On: true}, Action{Time: off, Room: r.Group})
+
+			t = off.Add(o.between(r.MinOff, r.MaxOff))
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].Time.Before(actions[j].Time)
+	})
+
+	return
+}
+
+// Run simulates presence every evening until the context is done, and returns the context's error. Actions that
+// fail are logged and don't stop the simulation.
+func (o *Simulator) Run(ctx context.Context) error {
+	date := time.Now()
+
+	// Finish an evening that started yesterday and ends after midnight.
+	if yesterday := date.AddDate(0, 0, -1); o.ends(yesterday).After(date) {
+		date = yesterday
+	}
+
+	if len(o.opts.Rooms) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	for {
+		actions := o.Plan(date)
+		if len(actions) > 0 && actions[len(actions)-1].Time.After(time.Now()) {
+			if err := o.runEvening(ctx, actions); err != nil {
+				return err
+			}
+		}
+
+		date = date.AddDate(0, 0, 1)
+	}
+}
+
+// ends returns the latest time the evening that starts on the date can end.
+func (o *Simulator) ends(date time.Time) time.Time {
+	year, month, d := date.Date()
+
+	return time.Date(year, month, d, 0, 0, 0, 0, date.Location()).Add(o.opts.End + 2*o.opts.Jitter)
+}
+
+func (o *Simulator) runEvening(ctx context.Context, actions []Action) error {
+	// Wait with resolving the rooms until the evening, so that the devices are current.
+	if err := clock.Sleep(ctx, time.Until(actions[0].Time)); err != nil {
+		return err
+	}
+
+	rooms, err := o.resolve(ctx)
+	if err != nil {
+		o.opts.Logger.Printf("cannot find the lights of the rooms: %v", err)
+	}
+
+	// When started in the middle of the evening, only the latest past action of each room is done.
+	now := time.Now()
+	latest := make(map[string]int)
+	for i, a := range actions {
+		if a.Time.Before(now) {
+			latest[a.Room] = i
+		}
+	}
+
+	for i, a := range actions {
+		if a.Time.Before(now) && latest[a.Room] != i {
+			continue
+		}
+
+		if err := clock.Sleep(ctx, time.Until(a.Time)); err != nil {
+			return err
+		}
+
+		devices := rooms[strings.ToLower(a.Room)]
+		if len(devices) == 0 {
+			o.opts.Logger.Printf("%s: no lights", a.Room)
+			continue
+		}
+
+		state := "off"
+		if a.On {
+			state = "on"
+		}
+
+		var failed []string
+		for _, d := range devices {
+			if err := controlifx.NewLight(o.conn, d).SetPower(ctx, a.On, o.opts.Transition); err != nil {
+				failed = append(failed, d.String()+": "+err.Error())
+			}
+		}
+
+		o.opts.Logger.Printf("%s: turned %s %d of %d lights", a.Room, state, len(devices)-len(failed), len(devices))
+		for _, f := range failed {
+			o.opts.Logger.Printf("%s: %s", a.Room, f)
+		}
+	}
+
+	return nil
+}
+
+// resolve discovers the devices and returns those of each room by the lowercased group label.
+func (o *Simulator) resolve(ctx context.Context) (map[string][]controlifx.Device, error) {
+	infos, err := scene.Discover(ctx, o.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make(map[string][]controlifx.Device)
+	for _, r := range o.opts.Rooms {
+		selector := scene.Selector{Kind: scene.GroupSelector, Value: r.Group}
+		rooms[strings.ToLower(r.Group)] = selector.Select(infos)
+	}
+
+	return rooms, nil
+}
+
+// jitter returns a random duration in the range [-Jitter, Jitter].
+func (o *Simulator) jitter() time.Duration {
+	return o.between(-o.opts.Jitter, o.opts.Jitter)
+}
+
+// between returns a random duration in the range [a, b].
+func (o *Simulator) between(a, b time.Duration) time.Duration {
+	if b <= a {
+		return a
+	}
+
+	return a + time.Duration(o.opts.Rand.Int63n(int64(b-a)+1))
+}
+
+// bounds returns the minimum and maximum of a duration with the defaults for those that aren't set. The default
+// minimum is capped at a maximum that is set, and a maximum below the minimum is raised to it.
+func bounds(lo, hi, defaultLo, defaultHi time.Duration) (time.Duration, time.Duration) {
+	if lo <= 0 {
+		lo = defaultLo
+		if hi > 0 && hi < lo {
+			lo = hi
+		}
+	}
+
+	if hi <= 0 {
+		hi = longer(defaultHi, lo)
+	} else if hi < lo {
+		hi = lo
+	}
+
+	return lo, hi
+}
+
+func longer(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,166 @@
+package presence
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestNew(t *testing.T) {
+	o := New(controlifx.Connection{}, Options{
+		Rooms: []Room{
+			{Group: "Kitchen"},
+			// Maxima below the default minima cap them instead of being replaced.
+			{Group: "Hall", MaxOn: 5 * time.Minute, MaxOff: time.Minute},
+			{Group: "Study", MinOn: 2 * time.Hour, MinOff: time.Hour, MaxOff: 10 * time.Minute},
+		},
+		Logger: log.New(ioutil.Discard, "", 0),
+	})
+
+	expected := []Room{
+		{Group: "Kitchen", MinOn: DefaultMinOn, MaxOn: DefaultMaxOn, MinOff: DefaultMinOff, MaxOff: DefaultMaxOff},
+		{Group: "Hall", MinOn: 5 * time.Minute, MaxOn: 5 * time.Minute, MinOff: time.Minute, MaxOff: time.Minute},
+		{Group: "Study", MinOn: 2 * time.Hour, MaxOn: 2 * time.Hour, MinOff: time.Hour, MaxOff: time.Hour},
+	}
+
+	for i, r := range o.opts.Rooms {
+		if r != expected[i] {
+			t.Errorf("expected '%+v', got '%+v'", expected[i], r)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	o := New(controlifx.Connection{}, Options{
+		Rooms: []Room{
+			{Group: "Kitchen"},
+			{Group: "Living Room", MinOn: 30 * time.Minute, MaxOn: 90 * time.Minute},
+		},
+		Start:  19 * time.Hour,
+		End:    time.Hour,
+		Jitter: 15 * time.Minute,
+		Logger: log.New(ioutil.Discard, "", 0),
+		Rand:   rand.New(rand.NewSource(1)),
+	})
+
+	date := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	earliest := time.Date(2020, 3, 1, 18, 45, 0, 0, time.UTC)
+	latest := time.Date(2020, 3, 2, 1, 30, 0, 0, time.UTC)
+
+	for i := 0; i < 20; i++ {
+		actions := o.Plan(date)
+		if len(actions) == 0 {
+			t.Fatal("expected actions")
+		}
+
+		on := make(map[string]time.Time)
+
+		for j, a := range actions {
+			if j > 0 && a.Time.Before(actions[j-1].Time) {
+				t.Fatalf("action %d at %v is before action %d at %v", j, a.Time, j-1, actions[j-1].Time)
+			}
+			if a.Time.Before(earliest) || a.Time.After(latest) {
+				t.Errorf("action %d at %v is outside [%v, %v]", j, a.Time, earliest, latest)
+			}
+
+			since, isOn := on[a.Room]
+			if a.On == isOn {
+				t.Fatalf("action %d turns %s on=%v twice in a row", j, a.Room, a.On)
+			}
+
+			if a.On {
+				on[a.Room] = a.Time
+			} else {
+				delete(on, a.Room)
+
+				if d := a.Time.Sub(since); a.Room == "Living Room" && d > 90*time.Minute {
+					t.Errorf("%s was on for %v", a.Room, d)
+				}
+			}
+		}
+
+		if len(on) > 0 {
+			t.Errorf("rooms left on: %v", on)
+		}
+	}
+}
+
+func TestSimulator_runEvening(t *testing.T) {
+	f, err := fakelight.New(1)
+	if err != nil {
+		t.Fatal("cannot start fake device:", err)
+	}
+	defer f.Close()
+
+	// The light is discovered by broadcasting to it, and is in the group of the kitchen.
+	service := make([]byte, 5)
+	service[0] = controlifx.UdpService
+	binary.LittleEndian.PutUint32(service[1:], uint32(f.Addr().Port))
+	f.Reply(controlifx.GetServiceType, controlifx.StateServiceType, service)
+
+	group := make([]byte, 56)
+	copy(group[16:48], "Kitchen")
+	f.Reply(controlifx.GetGroupType, controlifx.StateGroupType, group)
+	f.Reply(controlifx.GetLocationType, controlifx.StateLocationType, make([]byte, 56))
+
+	f.Update(func(s *fakelight.State) { s.Power = controlifx.OnPowerLevel })
+
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	conn, err := controlifx.ConnectFrom(loopback, f.Addr())
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+	defer conn.Close()
+
+	var logs bytes.Buffer
+	o := New(conn, Options{
+		Rooms:  []Room{{Group: "kitchen"}, {Group: "Living Room"}},
+		Logger: log.New(&logs, "", 0),
+	})
+
+	// Started in the middle of the evening, only the kitchen being turned off is replayed, and fails.
+	f.Drop(controlifx.LightSetPowerType, controlifx.DefaultRetries+1)
+
+	now := time.Now()
+	actions := []Action{
+		{Time: now.Add(-2 * time.Hour), Room: "kitchen", On: false},
+		{Time: now.Add(-time.Hour), Room: "kitchen", On: true},
+		{Time: now.Add(-30 * time.Minute), Room: "kitchen", On: false},
+		{Time: now.Add(-10 * time.Minute), Room: "Living Room", On: true},
+		// Past the discovery, which waits for NormalTimeout.
+		{Time: now.Add(time.Second), Room: "kitchen", On: true},
+	}
+
+	if err := o.runEvening(context.Background(), actions); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if n := f.Count(controlifx.LightSetPowerType); n != controlifx.DefaultRetries+2 {
+		t.Errorf("expected '%d' power messages, got '%d'", controlifx.DefaultRetries+2, n)
+	}
+	if v := f.State().Power; v != controlifx.OnPowerLevel {
+		t.Errorf("expected '%#x', got '%#x'", controlifx.OnPowerLevel, v)
+	}
+
+	d := controlifx.Device{Addr: f.Addr(), Mac: 1}
+	for _, expected := range []string{
+		"kitchen: turned off 0 of 1 lights\n",
+		"kitchen: " + d.String() + ": ",
+		"Living Room: no lights\n",
+		"kitchen: turned on 1 of 1 lights\n",
+	} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("expected '%s' in '%s'", expected, logs.String())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package controlifx
+
+//go:generate go run gen_products.go
+
+type (
+	// ProductFeatures are the capabilities of a product.
+	ProductFeatures struct {
+		Color             bool
+		Infrared          bool
+		Multizone         bool
+		ExtendedMultizone bool
+		Matrix            bool
+		Chain             bool
+		Hev               bool
+		Buttons           bool
+		Relays            bool
+
+		// MinKelvin and MaxKelvin are the supported color temperature range, or zero if the product doesn't emit
+		// light.
+		MinKelvin uint16
+		MaxKelvin uint16
+	}
+
+	// ProductUpgrade are the features of a product starting with a host firmware version.
+	ProductUpgrade struct {
+		Firmware FirmwareVersion
+		Features ProductFeatures
+	}
+
+	// Product is a LIFX device model as identified by the vendor and product of a StateVersion response.
+	Product struct {
+		VendorId   uint32
+		VendorName string
+		ProductId  uint32
+		Name       string
+		// Features are the features of the product with its earliest firmware. Use FeaturesAt to take upgrades into
+		// account.
+		Features ProductFeatures
+		// Upgrades are ordered by firmware version.
+		Upgrades []ProductUpgrade
+	}
+)
+
+// LookupProduct returns the product with the vendor and product IDs from the product registry, which is generated
+// from LIFX's public products.json.
+func LookupProduct(vendorId, productId uint32) (Product, bool) {
+	for _, p := range products {
+		if p.VendorId == vendorId && p.ProductId == productId {
+			return p, true
+		}
+	}
+
+	return Product{}, false
+}
+
+// Products returns all products in the product registry.
+func Products() []Product {
+	return append([]Product(nil), products...)
+}
+
+// LookupProduct returns the product of the responding device from the product registry.
+func (o StateVersionLanMessage) LookupProduct() (Product, bool) {
+	return LookupProduct(o.Vendor, o.Product)
+}
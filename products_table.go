@@ -0,0 +1,377 @@
+// Code generated by gen_products.go; DO NOT EDIT.
+
+package controlifx
+
+var products = []Product{
+	{VendorId: 1, VendorName: "LIFX", ProductId: 1, Name: "LIFX Original 1000",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 3, Name: "LIFX Color 650",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 10, Name: "LIFX White 800 (Low Voltage)",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 6500},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 11, Name: "LIFX White 800 (High Voltage)",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 6500},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 15, Name: "LIFX Color 1000",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 18, Name: "LIFX White 900 BR30 (Low Voltage)",
+		Features: ProductFeatures{MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 19, Name: "LIFX White 900 BR30 (High Voltage)",
+		Features: ProductFeatures{MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 20, Name: "LIFX Color 1000 BR30",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 22, Name: "LIFX Color 1000",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 27, Name: "LIFX A19",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 28, Name: "LIFX BR30",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 29, Name: "LIFX A19 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 30, Name: "LIFX BR30 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 31, Name: "LIFX Z",
+		Features: ProductFeatures{Color: true, Multizone: true, MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 32, Name: "LIFX Z",
+		Features: ProductFeatures{Color: true, Multizone: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 77), Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 2500, MaxKelvin: 9000}},
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 36, Name: "LIFX Downlight",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 37, Name: "LIFX Downlight",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 38, Name: "LIFX Beam",
+		Features: ProductFeatures{Color: true, Multizone: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 77), Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 2500, MaxKelvin: 9000}},
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 39, Name: "LIFX Downlight White to Warm",
+		Features: ProductFeatures{MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 40, Name: "LIFX Downlight",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 43, Name: "LIFX A19",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 44, Name: "LIFX BR30",
+		Features: ProductFeatures{Color: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 45, Name: "LIFX A19 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 46, Name: "LIFX BR30 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 2500, MaxKelvin: 9000},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(2, 80), Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 49, Name: "LIFX Mini Color",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 50, Name: "LIFX Mini White to Warm",
+		Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 6500},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(3, 70), Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 51, Name: "LIFX Mini White",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 52, Name: "LIFX GU10",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 53, Name: "LIFX GU10",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 55, Name: "LIFX Tile",
+		Features: ProductFeatures{Color: true, Matrix: true, Chain: true, MinKelvin: 2500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 57, Name: "LIFX Candle",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 59, Name: "LIFX Mini Color",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 60, Name: "LIFX Mini White to Warm",
+		Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 6500},
+		Upgrades: []ProductUpgrade{
+			{Firmware: NewFirmwareVersion(3, 70), Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000}},
+		},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 61, Name: "LIFX Mini White",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 62, Name: "LIFX A19",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 63, Name: "LIFX BR30",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 64, Name: "LIFX A19 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 65, Name: "LIFX BR30 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 66, Name: "LIFX Mini White",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 68, Name: "LIFX Candle",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 70, Name: "LIFX Switch",
+		Features: ProductFeatures{Buttons: true, Relays: true},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 71, Name: "LIFX Switch",
+		Features: ProductFeatures{Buttons: true, Relays: true},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 81, Name: "LIFX Candle White to Warm",
+		Features: ProductFeatures{MinKelvin: 2200, MaxKelvin: 6500},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 82, Name: "LIFX Filament Clear",
+		Features: ProductFeatures{MinKelvin: 2100, MaxKelvin: 2100},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 85, Name: "LIFX Filament Amber",
+		Features: ProductFeatures{MinKelvin: 2000, MaxKelvin: 2000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 87, Name: "LIFX Mini White",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 88, Name: "LIFX Mini White",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 89, Name: "LIFX Switch",
+		Features: ProductFeatures{Buttons: true, Relays: true},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 90, Name: "LIFX Clean",
+		Features: ProductFeatures{Color: true, Hev: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 91, Name: "LIFX Color",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 92, Name: "LIFX Color",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 93, Name: "LIFX A19 US",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 94, Name: "LIFX BR30",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 96, Name: "LIFX Candle White to Warm",
+		Features: ProductFeatures{MinKelvin: 2200, MaxKelvin: 6500},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 97, Name: "LIFX A19",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 98, Name: "LIFX BR30",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 99, Name: "LIFX Clean",
+		Features: ProductFeatures{Color: true, Hev: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 100, Name: "LIFX Filament Clear",
+		Features: ProductFeatures{MinKelvin: 2100, MaxKelvin: 2100},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 101, Name: "LIFX Filament Amber",
+		Features: ProductFeatures{MinKelvin: 2000, MaxKelvin: 2000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 109, Name: "LIFX A19 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 110, Name: "LIFX BR30 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 111, Name: "LIFX A19 Night Vision",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 112, Name: "LIFX BR30 Night Vision Intl",
+		Features: ProductFeatures{Color: true, Infrared: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 113, Name: "LIFX Mini WW US",
+		Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 114, Name: "LIFX Mini WW Intl",
+		Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 115, Name: "LIFX Switch",
+		Features: ProductFeatures{Buttons: true, Relays: true},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 116, Name: "LIFX Switch",
+		Features: ProductFeatures{Buttons: true, Relays: true},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 117, Name: "LIFX Z US",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 118, Name: "LIFX Z Intl",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 119, Name: "LIFX Beam US",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 120, Name: "LIFX Beam Intl",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 123, Name: "LIFX Color US",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 124, Name: "LIFX Color Intl",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 125, Name: "LIFX White to Warm US",
+		Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 126, Name: "LIFX White to Warm Intl",
+		Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 127, Name: "LIFX White US",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 128, Name: "LIFX White Intl",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 129, Name: "LIFX Color US",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 130, Name: "LIFX Color Intl",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 131, Name: "LIFX White To Warm US",
+		Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 132, Name: "LIFX White To Warm Intl",
+		Features: ProductFeatures{MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 133, Name: "LIFX White US",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 134, Name: "LIFX White Intl",
+		Features: ProductFeatures{MinKelvin: 2700, MaxKelvin: 2700},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 135, Name: "LIFX GU10 Color US",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 136, Name: "LIFX GU10 Color Intl",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 137, Name: "LIFX Candle Color US",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 138, Name: "LIFX Candle Color Intl",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 141, Name: "LIFX Neon US",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 142, Name: "LIFX Neon Intl",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 143, Name: "LIFX String US",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 144, Name: "LIFX String Intl",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 161, Name: "LIFX Outdoor Neon US",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 162, Name: "LIFX Outdoor Neon Intl",
+		Features: ProductFeatures{Color: true, Multizone: true, ExtendedMultizone: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 163, Name: "LIFX A19 US",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 164, Name: "LIFX BR30 US",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 165, Name: "LIFX A19 Intl",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 166, Name: "LIFX BR30 Intl",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 167, Name: "LIFX Downlight",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 168, Name: "LIFX Downlight",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 169, Name: "LIFX A21 1600lm US",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 170, Name: "LIFX A21 1600lm Intl",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 171, Name: "LIFX Round Spot US",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 173, Name: "LIFX Round Path US",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 174, Name: "LIFX Square Path US",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 175, Name: "LIFX PAR38 US",
+		Features: ProductFeatures{Color: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 176, Name: "LIFX Ceiling US",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+	{VendorId: 1, VendorName: "LIFX", ProductId: 177, Name: "LIFX Ceiling Intl",
+		Features: ProductFeatures{Color: true, Matrix: true, MinKelvin: 1500, MaxKelvin: 9000},
+	},
+}
@@ -0,0 +1,62 @@
+package controlifx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookupProduct(t *testing.T) {
+	p, ok := LookupProduct(Color1000VendorId, Color1000ProductId)
+	if !ok {
+		t.Fatal("product not found")
+	}
+
+	expected := Product{
+		VendorId:   1,
+		VendorName: "LIFX",
+		ProductId:  22,
+		Name:       "LIFX Color 1000",
+		Features: ProductFeatures{
+			Color:     true,
+			MinKelvin: 2500,
+			MaxKelvin: 9000,
+		},
+	}
+
+	if !reflect.DeepEqual(expected, p) {
+		t.Errorf("expected '%#v', got '%#v'", expected, p)
+	}
+}
+
+func TestLookupProduct2(t *testing.T) {
+	if p, ok := LookupProduct(1, 0xffff); ok {
+		t.Errorf("expected no product, got '%#v'", p)
+	}
+}
+
+func TestLookupProduct3(t *testing.T) {
+	tests := []struct {
+		vendorId, productId uint32
+		color               bool
+	}{
+		{Original1000VendorId, Original1000ProductId, Original1000Color},
+		{Color650VendorId, Color650ProductId, Color650Color},
+		{White800LowVVendorId, White800LowVProductId, White800LowVColor},
+		{White800HighVVendorId, White800HighVProductId, White800HighVColor},
+		{White900Br30LowVVendorId, White900Br30LowVProductId, White900Br30LowVColor},
+		{Color1000Br30VendorId, Color1000Br30ProductId, Color1000Br30Color},
+		{Color1000VendorId, Color1000ProductId, Color1000Color},
+	}
+
+	for _, test := range tests {
+		p, ok := StateVersionLanMessage{Vendor: test.vendorId, Product: test.productId}.LookupProduct()
+		if !ok {
+			t.Errorf("product %d not found", test.productId)
+			continue
+		}
+
+		if test.color != p.Features.Color {
+			t.Errorf("%s: expected color '%t', got '%t'", p.Name, test.color, p.Features.Color)
+		}
+	}
+}
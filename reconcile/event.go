@@ -0,0 +1,59 @@
+package reconcile
+
+import (
+	"time"
+
+	"github.com/yath/controlifx"
+)
+
+// EventKind is the kind of an event.
+type EventKind int
+
+const (
+	// Drifted is emitted when a device is found out of its desired state.
+	Drifted EventKind = iota
+	// Rebooted is emitted when a device restarted since the last poll, as noticed by watch.Tracker.
+	Rebooted
+	// Applied is emitted when the desired state was applied to a device.
+	Applied
+	// ApplyFailed is emitted when the desired state couldn't be applied to a device.
+	ApplyFailed
+	// InSync is emitted when a device is found in its desired state after it was applied.
+	InSync
+	// Unreachable is emitted when a device stops responding to polls.
+	Unreachable
+	// Reachable is emitted when an unreachable device responds again.
+	Reachable
+	// PollFailed is emitted by Run when a poll failed altogether. Its Device is zero.
+	PollFailed
+)
+
+var eventKindNames = [...]string{
+	Drifted:     "drifted",
+	Rebooted:    "rebooted",
+	Applied:     "applied",
+	ApplyFailed: "apply failed",
+	InSync:      "in sync",
+	Unreachable: "unreachable",
+	Reachable:   "reachable",
+	PollFailed:  "poll failed",
+}
+
+func (o EventKind) String() string {
+	if o < 0 || int(o) >= len(eventKindNames) {
+		return "unknown"
+	}
+
+	return eventKindNames[o]
+}
+
+// Event is something that happened to a device while reconciling it.
+type Event struct {
+	Kind   EventKind
+	Device controlifx.Device
+	Time   time.Time
+	// State is the state of the device at the poll, if it responded.
+	State controlifx.LightStateLanMessage
+	// Err is the error of ApplyFailed and PollFailed events.
+	Err error
+}
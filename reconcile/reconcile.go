@@ -0,0 +1,398 @@
+// Package reconcile keeps LIFX devices in a desired state, re-applying it when the devices drift from it, for
+// example after they were power-cycled at the wall and came back in their default state.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/effects"
+	"github.com/yath/controlifx/watch"
+)
+
+const (
+	// DefaultPollInterval is the poll interval of reconcilers whose options don't specify one.
+	DefaultPollInterval = 10 * time.Second
+
+	// DefaultMaxBackoff is the longest delay between attempts to re-apply the desired state of reconcilers whose
+	// options don't specify one.
+	DefaultMaxBackoff = 5 * time.Minute
+
+	// eventBuffer is the number of events buffered before further events are dropped.
+	eventBuffer = 64
+)
+
+type (
+	// Desired is the state a device is kept in.
+	Desired struct {
+		Power bool
+		Color controlifx.HSBK
+	}
+
+	// Options configure a reconciler.
+	Options struct {
+		// PollInterval is the interval at which the state of the devices is read.
+		PollInterval time.Duration
+		// MaxBackoff is the longest delay between attempts to re-apply the desired state to a device that keeps
+		// drifting. The delay starts at PollInterval and doubles with each attempt.
+		MaxBackoff time.Duration
+		// Duration is the duration of the transition to the desired state.
+		Duration time.Duration
+	}
+
+	// Status is the state of a device as last seen by the reconciler.
+	Status struct {
+		Device  controlifx.Device
+		Desired Desired
+
+		// Reachable is whether the device responded to the last poll.
+		Reachable bool
+		// InSync is whether the device was in the desired state at the last poll.
+		InSync bool
+		// LastSeen is the time of the last poll the device responded to.
+		LastSeen time.Time
+		// Uptime is the uptime of the device at LastSeen.
+		Uptime time.Duration
+
+		// Attempts is the number of times the desired state was applied since the device was last in sync.
+		Attempts int
+		// NextAttempt is the earliest time the desired state is applied again.
+		NextAttempt time.Time
+		// Err is the error of the last attempt, if it failed.
+		Err error
+
+		// skip is the number of polls left before the next attempt.
+		skip    int
+		tracker watch.Tracker
+		// features are the features of the device, or nil if they are unknown. resolved is set once they were
+		// queried, even if the product is unknown.
+		features *controlifx.ProductFeatures
+		resolved bool
+		// settled is the time the transition to the desired state last applied ends.
+		settled time.Time
+	}
+
+	// Reconciler polls devices and re-applies their desired state when they drift from it.
+	Reconciler struct {
+		conn   controlifx.Connection
+		opts   Options
+		events chan Event
+
+		mu      sync.Mutex
+		devices map[controlifx.MAC]*Status
+	}
+)
+
+// New returns a reconciler without devices.
+func New(conn controlifx.Connection, opts Options) *Reconciler {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+
+	return &Reconciler{
+		conn:    conn,
+		opts:    opts,
+		events:  make(chan Event, eventBuffer),
+		devices: make(map[controlifx.MAC]*Status),
+	}
+}
+
+// Set sets the desired state of the device. It's applied at the next poll if the device isn't in it.
+func (o *Reconciler) Set(device controlifx.Device, desired Desired) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	s, ok := o.devices[device.Mac]
+	if !ok {
+		s = &Status{}
+		o.devices[device.Mac] = s
+	}
+
+	s.Device = device
+	s.Desired = desired
+	s.InSync = false
+	s.Attempts = 0
+	s.NextAttempt = time.Time{}
+	s.skip = 0
+	s.settled = time.Time{}
+}
+
+// Remove stops reconciling the device.
+func (o *Reconciler) Remove(mac controlifx.MAC) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.devices, mac)
+}
+
+// Status returns the status of all devices, ordered by MAC address.
+func (o *Reconciler) Status() []Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	statuses := make([]Status, 0, len(o.devices))
+	for _, s := range o.devices {
+		statuses = append(statuses, *s)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Device.Mac < statuses[j].Device.Mac
+	})
+
+	return statuses
+}
+
+// Events returns the channel the events are sent on. Events are dropped while the channel is full, so it should be
+// drained continuously.
+func (o *Reconciler) Events() <-chan Event {
+	return o.events
+}
+
+// Run polls the devices every PollInterval until the context is done, and returns the context's error.
+func (o *Reconciler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := o.Poll(ctx); err != nil && ctx.Err() == nil {
+			o.emit(Event{Kind: PollFailed, Time: time.Now(), Err: err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll reads the state and uptime of all devices once, and re-applies the desired state to the devices that drifted
+// from it and whose backoff has passed. The features of devices are read at their first poll, so that colors they
+// can't show, such as colors on white-only products, aren't mistaken for drift.
+func (o *Reconciler) Poll(ctx context.Context) error {
+	o.mu.Lock()
+	var unresolved []controlifx.Device
+	devices := make([]controlifx.Device, 0, len(o.devices))
+	for _, s := range o.devices {
+		devices = append(devices, s.Device)
+		if !s.resolved {
+			unresolved = append(unresolved, s.Device)
+		}
+	}
+	o.mu.Unlock()
+
+	if len(devices) == 0 {
+		return nil
+	}
+
+	for _, d := range unresolved {
+		features, err := controlifx.NewLight(o.conn, d).Features(ctx)
+		if err != nil && (errors.Is(err, controlifx.ErrNoResponse) || ctx.Err() != nil) {
+			// Tried again at the next poll.
+			continue
+		}
+
+		o.mu.Lock()
+		if s, tracked := o.devices[d.Mac]; tracked {
+			s.resolved = true
+			if err == nil {
+				s.features = &features
+			}
+		}
+		o.mu.Unlock()
+	}
+
+	states, err := effects.Snapshot(ctx, o.conn, devices)
+	if err != nil {
+		return err
+	}
+
+	infos, err := o.conn.SendToAndGetContext(ctx, controlifx.GetInfo(), devices,
+		controlifx.TypeFilter(controlifx.StateInfoType))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, d := range devices {
+		var info *controlifx.StateInfoLanMessage
+		if recMsg, ok := infos[d]; ok {
+			info = recMsg.Payload.(*controlifx.StateInfoLanMessage)
+		}
+
+		state, ok := states[d.Mac]
+
+		o.mu.Lock()
+		s, tracked := o.devices[d.Mac]
+		var (
+			apply    bool
+			desired  Desired
+			features *controlifx.ProductFeatures
+		)
+		if tracked {
+			var events []Event
+			events, apply = s.observe(now, state, ok, info)
+			desired, features = s.Desired, s.features
+			for _, e := range events {
+				o.emit(e)
+			}
+		}
+		o.mu.Unlock()
+
+		if !apply {
+			continue
+		}
+
+		err := o.apply(ctx, d, desired, features)
+
+		o.mu.Lock()
+		if s, tracked := o.devices[d.Mac]; tracked && s.Desired == desired {
+			// The transition starts once the messages were sent, which may be well after the poll.
+			o.emit(s.applied(time.Now(), err, o.opts))
+		}
+		o.mu.Unlock()
+	}
+
+	return nil
+}
+
+// apply sends the desired state to the device. The color is adapted to the features, if they are known, so that the
+// device shows the color it's later compared with.
+func (o *Reconciler) apply(ctx context.Context, d controlifx.Device, desired Desired, features *controlifx.ProductFeatures) error {
+	light := controlifx.NewLight(o.conn, d)
+
+	color := desired.Color
+	if features != nil {
+		color, _ = features.AdaptColor(color)
+	}
+
+	if err := light.SetColor(ctx, color, o.opts.Duration); err != nil {
+		return err
+	}
+
+	return light.SetPower(ctx, desired.Power, o.opts.Duration)
+}
+
+func (o *Reconciler) emit(e Event) {
+	select {
+	case o.events <- e:
+	default:
+	}
+}
+
+// observe updates the status with a poll result and returns the resulting events and whether the desired state
+// should be applied now.
+func (o *Status) observe(now time.Time, state controlifx.LightStateLanMessage, ok bool, info *controlifx.StateInfoLanMessage) (events []Event, apply bool) {
+	event := func(kind EventKind) {
+		events = append(events, Event{Kind: kind, Device: o.Device, Time: now, State: state})
+	}
+
+	if !ok {
+		if o.Reachable {
+			event(Unreachable)
+		}
+		o.Reachable = false
+
+		return
+	}
+
+	if !o.Reachable && !o.LastSeen.IsZero() {
+		event(Reachable)
+	}
+	o.Reachable = true
+	o.LastSeen = now
+
+	if info != nil {
+		if _, rebooted := o.tracker.Observe(now, *info); rebooted {
+			event(Rebooted)
+		}
+		o.Uptime = time.Duration(info.Uptime)
+	}
+
+	if now.Before(o.settled) {
+		// The device is still on its way to the desired state.
+		return
+	}
+
+	if o.Desired.matches(state, o.features) {
+		if !o.InSync && o.Attempts > 0 {
+			event(InSync)
+		}
+		o.InSync = true
+		o.Attempts = 0
+		o.NextAttempt = time.Time{}
+		o.Err = nil
+		o.skip = 0
+
+		return
+	}
+
+	if o.InSync || o.Attempts == 0 {
+		event(Drifted)
+	}
+	o.InSync = false
+
+	if o.skip > 0 {
+		o.skip--
+		return
+	}
+
+	return events, true
+}
+
+// applied records an attempt at the poll at the time to apply the desired state and returns its event.
+func (o *Status) applied(now time.Time, err error, opts Options) Event {
+	o.Attempts++
+	o.skip = backoff(o.Attempts, opts)
+	o.NextAttempt = now.Add(time.Duration(o.skip+1) * opts.PollInterval)
+	o.Err = err
+	if err == nil {
+		o.settled = now.Add(opts.Duration)
+	}
+
+	e := Event{Kind: Applied, Device: o.Device, Time: now, Err: err}
+	if err != nil {
+		e.Kind = ApplyFailed
+	}
+
+	return e
+}
+
+// backoff returns the number of polls to skip after the attempt. The delay doubles with each attempt, starting at
+// the next poll, up to MaxBackoff.
+func backoff(attempts int, opts Options) int {
+	maxPolls := int(opts.MaxBackoff / opts.PollInterval)
+
+	polls := 1
+	for i := 1; i < attempts && polls < maxPolls; i++ {
+		polls *= 2
+	}
+
+	if polls > maxPolls {
+		polls = maxPolls
+	}
+	if polls < 1 {
+		polls = 1
+	}
+
+	return polls - 1
+}
+
+// matches returns whether the device is in the desired state, give or take the rounding of the device. If the
+// features of the device are known, the desired color is first adapted to what the device can show.
+func (o Desired) matches(state controlifx.LightStateLanMessage, features *controlifx.ProductFeatures) bool {
+	color := o.Color
+	if features != nil {
+		color, _ = features.AdaptColor(color)
+	}
+
+	return o.Power == (state.Power != controlifx.OffPowerLevel) && state.Color.Between(color, color)
+}
@@ -0,0 +1,264 @@
+package reconcile
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestBackoff(t *testing.T) {
+	opts := Options{PollInterval: 10 * time.Second, MaxBackoff: time.Minute}
+
+	for attempts, expected := range []int{1: 0, 2: 1, 3: 3, 4: 5, 5: 5} {
+		if attempts == 0 {
+			continue
+		}
+
+		if v := backoff(attempts, opts); expected != v {
+			t.Errorf("backoff(%d): expected %d, got %d", attempts, expected, v)
+		}
+	}
+}
+
+func kinds(events []Event) (kinds []EventKind) {
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+
+	return
+}
+
+func checkEvents(t *testing.T, i int, expected []EventKind, events []Event) {
+	t.Helper()
+
+	v := kinds(events)
+	if len(v) != len(expected) {
+		t.Errorf("%d: events: expected %v, got %v", i, expected, v)
+		return
+	}
+
+	for j := range v {
+		if v[j] != expected[j] {
+			t.Errorf("%d: events: expected %v, got %v", i, expected, v)
+			return
+		}
+	}
+}
+
+func TestObserve(t *testing.T) {
+	opts := Options{PollInterval: time.Second, MaxBackoff: time.Minute}
+	color := controlifx.HSBK{Hue: 1000, Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500}
+	s := &Status{Desired: Desired{Power: true, Color: color}}
+
+	inSync := controlifx.LightStateLanMessage{Color: color, Power: controlifx.OnPowerLevel}
+	// The default state of a bulb after it was power-cycled.
+	reset := controlifx.LightStateLanMessage{
+		Color: controlifx.HSBK{Brightness: 0xffff, Kelvin: 3500},
+		Power: controlifx.OnPowerLevel,
+	}
+
+	now := time.Now()
+	info := func(uptime time.Duration) *controlifx.StateInfoLanMessage {
+		return &controlifx.StateInfoLanMessage{Uptime: uint64(uptime)}
+	}
+
+	steps := []struct {
+		state  controlifx.LightStateLanMessage
+		ok     bool
+		uptime time.Duration
+		events []EventKind
+		apply  bool
+	}{
+		{inSync, true, time.Hour, nil, false},
+		{reset, false, 0, []EventKind{Unreachable}, false},
+		{reset, true, time.Second, []EventKind{Reachable, Rebooted, Drifted}, true},
+		// Attempt 1 was made, so the next poll tries again.
+		{reset, true, 2 * time.Second, nil, true},
+		// Attempt 2 was made, so one poll is skipped.
+		{reset, true, 3 * time.Second, nil, false},
+		{reset, true, 4 * time.Second, nil, true},
+		{inSync, true, 5 * time.Second, []EventKind{InSync}, false},
+	}
+
+	for i, step := range steps {
+		events, apply := s.observe(now, step.state, step.ok, info(step.uptime))
+
+		checkEvents(t, i, step.events, events)
+
+		if apply != step.apply {
+			t.Errorf("%d: apply: expected %v, got %v", i, step.apply, apply)
+		}
+
+		if apply {
+			if e := s.applied(now, nil, opts); e.Kind != Applied {
+				t.Errorf("%d: applied: expected %v, got %v", i, Applied, e.Kind)
+			}
+		}
+
+		now = now.Add(opts.PollInterval)
+	}
+
+	if !s.InSync || s.Attempts != 0 {
+		t.Errorf("expected in sync without attempts, got %+v", s)
+	}
+}
+
+func TestObserve_Transition(t *testing.T) {
+	opts := Options{PollInterval: time.Second, MaxBackoff: time.Minute, Duration: 3 * time.Second}
+	from := controlifx.HSBK{Brightness: 0xffff, Kelvin: 3500}
+	to := controlifx.HSBK{Hue: 0x8000, Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500}
+	s := &Status{Desired: Desired{Power: true, Color: to}}
+
+	state := func(c controlifx.HSBK) controlifx.LightStateLanMessage {
+		return controlifx.LightStateLanMessage{Color: c, Power: controlifx.OnPowerLevel}
+	}
+
+	now := time.Now()
+
+	events, apply := s.observe(now, state(from), true, nil)
+	checkEvents(t, 0, []EventKind{Drifted}, events)
+	if !apply {
+		t.Fatal("expected apply")
+	}
+	s.applied(now, nil, opts)
+
+	// The polls during the transition see the colors on the way.
+	for i, c := range []controlifx.HSBK{
+		controlifx.Interpolate(from, to, 0.3, controlifx.HSBKInterpolation),
+		controlifx.Interpolate(from, to, 0.6, controlifx.HSBKInterpolation),
+	} {
+		now = now.Add(opts.PollInterval)
+
+		if events, apply := s.observe(now, state(c), true, nil); len(events) > 0 || apply {
+			t.Errorf("%d: expected no events and no apply during the transition, got %v, %v", i, kinds(events), apply)
+		}
+	}
+
+	now = now.Add(opts.PollInterval)
+	events, apply = s.observe(now, state(to), true, nil)
+	checkEvents(t, 3, []EventKind{InSync}, events)
+	if apply {
+		t.Error("expected no apply after the transition")
+	}
+}
+
+func TestDesired_matches(t *testing.T) {
+	color := controlifx.HSBK{Hue: 0x5555, Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500}
+	white := &controlifx.ProductFeatures{MinKelvin: 2700, MaxKelvin: 6500}
+	warm := controlifx.HSBK{Brightness: 0x8000, Kelvin: 1500}
+
+	tests := []struct {
+		desired  controlifx.HSBK
+		state    controlifx.HSBK
+		features *controlifx.ProductFeatures
+		expected bool
+	}{
+		{color, color, nil, true},
+		// Rounded by the device.
+		{color, controlifx.HSBK{Hue: 0x5553, Saturation: 0xfffe, Brightness: 0x7ffd, Kelvin: 3500}, nil, true},
+		{color, controlifx.HSBK{Hue: 0x6000, Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500}, nil, false},
+		{color, controlifx.HSBK{Hue: 0x5555, Saturation: 0xffff, Brightness: 0xffff, Kelvin: 3500}, nil, false},
+		// A white-only product drops the saturation.
+		{warm, warm, white, false},
+		{warm, controlifx.HSBK{Brightness: 0x8000, Kelvin: 2700}, white, true},
+	}
+
+	for i, test := range tests {
+		state := controlifx.LightStateLanMessage{Color: test.state, Power: controlifx.OnPowerLevel}
+
+		if v := (Desired{Power: true, Color: test.desired}).matches(state, test.features); test.expected != v {
+			t.Errorf("%d: expected %v, got %v", i, test.expected, v)
+		}
+	}
+
+	adapted, _ := white.AdaptColor(color)
+	state := controlifx.LightStateLanMessage{Color: adapted, Power: controlifx.OnPowerLevel}
+	if !(Desired{Power: true, Color: color}).matches(state, white) {
+		t.Errorf("expected %v to match %v on a white-only product", adapted, color)
+	}
+}
+
+func TestReconciler_Poll(t *testing.T) {
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	conn, err := controlifx.ConnectFrom(loopback, loopback)
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+	defer conn.Close()
+
+	f, err := fakelight.New(1)
+	if err != nil {
+		t.Fatal("cannot start fake device:", err)
+	}
+	defer f.Close()
+
+	// The device reports the color it was sent slightly off.
+	f.Update(func(s *fakelight.State) {
+		s.Color = fakelight.HSBK{Hue: 0x5553, Saturation: 0xfffe, Brightness: 0x7ffd, Kelvin: 3500}
+		s.Power = controlifx.OnPowerLevel
+	})
+
+	r := New(conn, Options{PollInterval: time.Second})
+	r.Set(controlifx.Device{Addr: f.Addr(), Mac: 1}, Desired{
+		Power: true,
+		Color: controlifx.HSBK{Hue: 0x5555, Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500},
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := r.Poll(context.Background()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if n := f.Count(controlifx.LightSetColorType); n != 0 {
+		t.Errorf("expected '0' colors set, got '%d'", n)
+	}
+	if s := r.Status()[0]; !s.InSync {
+		t.Errorf("expected in sync, got '%+v'", s)
+	}
+}
+
+func TestReconciler_Poll_White(t *testing.T) {
+	loopback := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	conn, err := controlifx.ConnectFrom(loopback, loopback)
+	if err != nil {
+		t.Fatal("cannot connect:", err)
+	}
+	defer conn.Close()
+
+	f, err := fakelight.New(1)
+	if err != nil {
+		t.Fatal("cannot start fake device:", err)
+	}
+	defer f.Close()
+
+	// A White 800, which drops the saturation of the colors it's sent.
+	f.Update(func(s *fakelight.State) {
+		s.Vendor, s.Product = 1, 10
+		s.White = true
+	})
+
+	r := New(conn, Options{PollInterval: time.Second})
+	r.Set(controlifx.Device{Addr: f.Addr(), Mac: 1}, Desired{
+		Power: true,
+		Color: controlifx.HSBK{Hue: 0x1000, Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500},
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := r.Poll(context.Background()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// The color is applied once, and then the device stays in sync.
+	if n := f.Count(controlifx.LightSetColorType); n != 1 {
+		t.Errorf("expected '1' color set, got '%d'", n)
+	}
+	if s := r.Status()[0]; !s.InSync {
+		t.Errorf("expected in sync, got '%+v'", s)
+	}
+}
@@ -0,0 +1,360 @@
+package scene
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/effects"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PowerOn  = "on"
+	PowerOff = "off"
+)
+
+// waveforms are the waveforms by their names in scene files.
+var waveforms = map[string]uint8{
+	"saw":       controlifx.SawWaveform,
+	"sine":      controlifx.SineWaveform,
+	"half_sine": controlifx.HalfSineWaveform,
+	"triangle":  controlifx.TriangleWaveform,
+	"pulse":     controlifx.PulseWaveform,
+}
+
+type (
+	// File is a declarative scene as stored in YAML or JSON scene files, for example:
+	//
+	//	name: evening
+	//	states:
+	//	  - selector: group:Living Room
+	//	    power: on
+	//	    color: kelvin:2700 brightness:0.4
+	//	    duration: 30
+	//	  - selector: label:Porch
+	//	    power: off
+	//	  - selector: id:d073d5010203
+	//	    color: red
+	//	    waveform: {type: pulse, period: 0.5, cycles: 3}
+	//
+	// The states are applied in order, so later states override earlier ones for devices that both select.
+	File struct {
+		Name   string  `json:"name,omitempty" yaml:"name,omitempty"`
+		States []State `json:"states" yaml:"states"`
+	}
+
+	// State is the state of the devices a selector selects. Fields left empty aren't changed.
+	State struct {
+		Selector string `json:"selector" yaml:"selector"`
+		// Power is either PowerOn or PowerOff.
+		Power string `json:"power,omitempty" yaml:"power,omitempty"`
+		// Color is in the syntax of controlifx.ParseColor.
+		Color string `json:"color,omitempty" yaml:"color,omitempty"`
+		// Duration is the duration of the power and color transitions in seconds.
+		Duration float64 `json:"duration,omitempty" yaml:"duration,omitempty"`
+		// Waveform runs a waveform with the color instead of transitioning to it.
+		Waveform *Waveform `json:"waveform,omitempty" yaml:"waveform,omitempty"`
+	}
+
+	// Waveform is a waveform run by the devices.
+	Waveform struct {
+		// Type is one of saw, sine, half_sine, triangle or pulse.
+		Type string `json:"type" yaml:"type"`
+		// Period is the duration of a cycle in seconds, or effects.DefaultPeriod if 0.
+		Period float64 `json:"period,omitempty" yaml:"period,omitempty"`
+		// Cycles is the number of cycles, or 1 if 0.
+		Cycles float64 `json:"cycles,omitempty" yaml:"cycles,omitempty"`
+		// SkewRatio is the skew ratio in the range [0, 1], or 0.5 if unset.
+		SkewRatio *float64 `json:"skew_ratio,omitempty" yaml:"skew_ratio,omitempty"`
+		// Persist keeps the color after the waveform instead of returning to the original color.
+		Persist bool `json:"persist,omitempty" yaml:"persist,omitempty"`
+	}
+
+	// Outcome is the result of applying a state to a device.
+	Outcome struct {
+		Selector string
+		Device   controlifx.Device
+		Label    string
+		// Err is non-nil if the device couldn't be set to the state.
+		Err error
+	}
+
+	// compiledState is a State with its fields parsed.
+	compiledState struct {
+		selector Selector
+		power    *bool
+		color    *controlifx.PartialHSBK
+		duration time.Duration
+		waveform *controlifx.LightSetWaveformLanMessage
+	}
+)
+
+// LoadFile reads a scene file. Files with the .json extension are read as JSON, all others as YAML.
+func LoadFile(name string) (File, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return File{}, err
+	}
+
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		return LoadJSON(bytes.NewReader(data))
+	}
+
+	return LoadYAML(bytes.NewReader(data))
+}
+
+// LoadYAML reads and validates a scene in YAML. Unknown fields are an error.
+func LoadYAML(r io.Reader) (o File, err error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	if err = dec.Decode(&o); err != nil {
+		return
+	}
+
+	err = o.Validate()
+
+	return
+}
+
+// LoadJSON reads and validates a scene in JSON. Unknown fields are an error.
+func LoadJSON(r io.Reader) (o File, err error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(&o); err != nil {
+		return
+	}
+
+	err = o.Validate()
+
+	return
+}
+
+// Validate checks that the selectors, colors and waveforms of the states parse and are in range. The error is a
+// *controlifx.ValidationError.
+func (o File) Validate() error {
+	_, err := o.compile()
+
+	return err
+}
+
+func (o File) compile() ([]compiledState, error) {
+	var (
+		states   = make([]compiledState, len(o.States))
+		problems []string
+	)
+
+	problem := func(i int, format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf("states[%d]: ", i)+fmt.Sprintf(format, a...))
+	}
+
+	if len(o.States) == 0 {
+		problems = append(problems, "no states")
+	}
+
+	for i, s := range o.States {
+		c := &states[i]
+
+		var err error
+		if c.selector, err = ParseSelector(s.Selector); err != nil {
+			problem(i, "%v", err)
+		}
+
+		switch s.Power {
+		case "":
+		case PowerOn, PowerOff:
+			on := s.Power == PowerOn
+			c.power = &on
+		default:
+			problem(i, "power %q is neither %s nor %s", s.Power, PowerOn, PowerOff)
+		}
+
+		if s.Color != "" {
+			color, err := controlifx.ParseColor(s.Color)
+			if err != nil {
+				problem(i, "%v", err)
+			}
+			c.color = &color
+		}
+
+		if math.IsNaN(s.Duration) || math.IsInf(s.Duration, 0) {
+			problem(i, "duration %v is invalid", s.Duration)
+		} else if s.Duration < 0 {
+			problem(i, "duration %v is negative", s.Duration)
+		}
+		c.duration = time.Duration(s.Duration * float64(time.Second))
+
+		if s.Power == "" && s.Color == "" {
+			problem(i, "neither power nor color is set")
+		}
+
+		if w := s.Waveform; w != nil {
+			if s.Color == "" {
+				problem(i, "waveform without a color")
+			}
+
+			waveform, ok := waveforms[w.Type]
+			if !ok {
+				problem(i, "unknown waveform type %q", w.Type)
+			}
+
+			period := time.Duration(w.Period * float64(time.Second))
+			if w.Period < 0 || math.IsNaN(w.Period) {
+				problem(i, "waveform period %v is negative", w.Period)
+			} else if period == 0 {
+				period = effects.DefaultPeriod
+			}
+
+			cycles := w.Cycles
+			if cycles < 0 || math.IsNaN(cycles) {
+				problem(i, "waveform cycles %v is negative", cycles)
+			} else if cycles == 0 {
+				cycles = 1
+			}
+
+			skewRatio := 0.5
+			if w.SkewRatio != nil {
+				if skewRatio = *w.SkewRatio; skewRatio < 0 || skewRatio > 1 {
+					problem(i, "waveform skew ratio %v is out of range [0, 1]", skewRatio)
+				}
+			}
+
+			c.waveform = &controlifx.LightSetWaveformLanMessage{
+				Transient: !w.Persist,
+				Period:    controlifx.DurationToMs(period),
+				Cycles:    float32(cycles),
+				SkewRatio: effects.SkewRatio(skewRatio),
+				Waveform:  waveform,
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		payload := "scene"
+		if o.Name != "" {
+			payload += " " + o.Name
+		}
+
+		return nil, &controlifx.ValidationError{Payload: payload, Problems: problems}
+	}
+
+	return states, nil
+}
+
+// Apply discovers the devices on the network and applies the scene to them as described by ApplyTo.
+func Apply(ctx context.Context, conn controlifx.Connection, file File) ([]Outcome, error) {
+	infos, err := Discover(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplyTo(ctx, conn, file, infos)
+}
+
+// Discover discovers the devices on the network and describes them, so that selectors can select them.
+func Discover(ctx context.Context, conn controlifx.Connection) ([]controlifx.DeviceInfo, error) {
+	devices, err := conn.DiscoverAllDevices(controlifx.NormalTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.Describe(ctx, devices)
+}
+
+// ApplyTo applies the states of the scene in order to the devices their selectors select. Every message is
+// acknowledged by the devices and resent if lost. The returned outcomes list every device each state was applied
+// to; a device failing doesn't stop the others. The error is non-nil if the scene is invalid, if a selector selects
+// no devices, or if any device failed.
+func ApplyTo(ctx context.Context, conn controlifx.Connection, file File, infos []controlifx.DeviceInfo) (outcomes []Outcome, err error) {
+	states, err := file.compile()
+	if err != nil {
+		return
+	}
+
+	labels := make(map[controlifx.MAC]string, len(infos))
+	for _, info := range infos {
+		labels[info.Device.Mac] = info.Label
+	}
+
+	var (
+		problems []string
+		failed   int
+	)
+
+	for _, s := range states {
+		devices := s.selector.Select(infos)
+		if len(devices) == 0 {
+			problems = append(problems, fmt.Sprintf("selector %s selects no devices", s.selector))
+			continue
+		}
+
+		var current map[controlifx.MAC]controlifx.LightStateLanMessage
+		if s.color != nil && s.waveform == nil {
+			if current, err = effects.Snapshot(ctx, conn, devices); err != nil {
+				return
+			}
+		}
+
+		for _, d := range devices {
+			outcome := Outcome{
+				Selector: s.selector.String(),
+				Device:   d,
+				Label:    labels[d.Mac],
+				Err:      s.apply(ctx, controlifx.NewLight(conn, d), current),
+			}
+			if outcome.Err != nil {
+				failed++
+			}
+
+			outcomes = append(outcomes, outcome)
+		}
+	}
+
+	if failed > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d devices failed", failed, len(outcomes)))
+	}
+
+	if len(problems) > 0 {
+		err = fmt.Errorf("cannot apply scene: %s", strings.Join(problems, "; "))
+	}
+
+	return
+}
+
+func (o compiledState) apply(ctx context.Context, light controlifx.Light, current map[controlifx.MAC]controlifx.LightStateLanMessage) error {
+	if o.color != nil && o.waveform == nil {
+		state, ok := current[light.Device.Mac]
+		if !ok {
+			return controlifx.ErrNoResponse
+		}
+
+		if err := light.SetColor(ctx, o.color.Apply(state.Color), o.duration); err != nil {
+			return err
+		}
+	}
+
+	if o.power != nil {
+		if err := light.SetPower(ctx, *o.power, o.duration); err != nil {
+			return err
+		}
+	}
+
+	if o.waveform != nil {
+		waveform := o.color.WaveformOptional(*o.waveform)
+		if err := light.Set(ctx, controlifx.LightSetWaveformOptional(waveform)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
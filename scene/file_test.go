@@ -0,0 +1,136 @@
+package scene
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/yath/controlifx"
+)
+
+const testYAML = `
+name: evening
+states:
+  - selector: group:Living Room
+    power: on
+    color: kelvin:2700 brightness:0.4
+    duration: 30
+  - selector: label:Porch
+    power: off
+  - selector: id:d073d5010203
+    color: red
+    waveform: {type: pulse, period: 0.5, cycles: 3}
+`
+
+func TestLoadYAML(t *testing.T) {
+	f, err := LoadYAML(strings.NewReader(testYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Name != "evening" || len(f.States) != 3 {
+		t.Fatalf("expected the file 'evening' with '3' states, got '%+v'", f)
+	}
+	if s := f.States[0]; s.Power != PowerOn || s.Duration != 30 {
+		t.Errorf("expected power 'on' over '30' seconds, got '%+v'", s)
+	}
+
+	states, err := f.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if on := states[1].power; on == nil || *on {
+		t.Errorf("expected 'false', got '%v'", on)
+	}
+	w := states[2].waveform
+	if w == nil || w.Waveform != controlifx.PulseWaveform || w.Period != 500 || w.Cycles != 3 || !w.Transient {
+		t.Errorf("expected a transient pulse of '3' cycles of '500' ms, got '%+v'", w)
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	f, err := LoadJSON(strings.NewReader(`{"states": [{"selector": "all", "power": "on"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.States) != 1 || f.States[0].Selector != "all" {
+		t.Errorf("expected one state for 'all', got '%+v'", f)
+	}
+
+	if _, err := LoadJSON(strings.NewReader(`{"states": [{"selector": "all", "brightness": 1}]}`)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestLoadYAMLUnknownField(t *testing.T) {
+	if _, err := LoadYAML(strings.NewReader("states:\n  - selector: all\n    colour: red\n")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	f := File{States: []State{
+		{Selector: "room:Kitchen", Power: "yes"},
+		{Selector: "all", Color: "kelvin:12000", Duration: -1},
+		{Selector: "all", Color: "red", Waveform: &Waveform{Type: "square"}},
+		{Selector: "all"},
+		{Selector: "all", Power: PowerOn, Duration: math.NaN()},
+	}}
+
+	err := f.Validate()
+
+	var verr *controlifx.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *controlifx.ValidationError, got %T", err)
+	}
+
+	if len(verr.Problems) != 7 {
+		t.Errorf("expected '7' problems, got '%q'", verr.Problems)
+	} else if p := verr.Problems[6]; !strings.Contains(p, "duration NaN is invalid") {
+		t.Errorf("expected the NaN duration to be invalid, got '%s'", p)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	mac, _ := controlifx.ParseMAC("d0:73:d5:01:02:03")
+	info := controlifx.DeviceInfo{
+		Device:   controlifx.Device{Mac: mac},
+		Label:    "Desk",
+		Group:    controlifx.StateGroupLanMessage{Label: "Office"},
+		Location: controlifx.StateLocationLanMessage{Label: "Home"},
+	}
+
+	tests := []struct {
+		selector string
+		expected bool
+	}{
+		{"all", true},
+		{"label:desk", true},
+		{"label:Lamp", false},
+		{"group:Office", true},
+		{"location:home", true},
+		{"id:d073d5010203", true},
+		{"id:d073d5010204", false},
+	}
+
+	for _, test := range tests {
+		s, err := ParseSelector(test.selector)
+		if err != nil {
+			t.Errorf("%s: error: %v", test.selector, err)
+			continue
+		}
+		if s.String() != test.selector {
+			t.Errorf("expected '%s', got '%s'", test.selector, s)
+		}
+		if v := s.Match(info); v != test.expected {
+			t.Errorf("%s: expected '%v', got '%v'", test.selector, test.expected, v)
+		}
+	}
+
+	for _, s := range []string{"", "desk", "label:", "id:xyz", "room:Office"} {
+		if _, err := ParseSelector(s); err == nil {
+			t.Errorf("ParseSelector(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package scene captures the state of LIFX lights and restores it later.
+package scene
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/effects"
+)
+
+type (
+	// Color is a controlifx.HSBK with field names suited for configuration files.
+	Color struct {
+		Hue        uint16 `json:"hue" yaml:"hue"`
+		Saturation uint16 `json:"saturation" yaml:"saturation"`
+		Brightness uint16 `json:"brightness" yaml:"brightness"`
+		Kelvin     uint16 `json:"kelvin" yaml:"kelvin"`
+	}
+
+	// Light is the state of a light in a scene.
+	Light struct {
+		Mac controlifx.MAC `json:"mac" yaml:"mac"`
+		// Addr is the address the light had when the scene was captured, used when applying the scene without
+		// discovering the devices first.
+		Addr  string `json:"addr,omitempty" yaml:"addr,omitempty"`
+		Label string `json:"label,omitempty" yaml:"label,omitempty"`
+		Power bool   `json:"power" yaml:"power"`
+		Color Color  `json:"color" yaml:"color"`
+	}
+
+	// Scene is the state of a set of lights.
+	Scene struct {
+		Name   string  `json:"name,omitempty" yaml:"name,omitempty"`
+		Lights []Light `json:"lights" yaml:"lights"`
+	}
+)
+
+// NewColor returns the Color of the HSBK.
+func NewColor(c controlifx.HSBK) Color {
+	return Color(c)
+}
+
+// HSBK returns the HSBK of the color.
+func (o Color) HSBK() controlifx.HSBK {
+	return controlifx.HSBK(o)
+}
+
+// Device returns the device of the light at the address it had when the scene was captured.
+func (o Light) Device() (controlifx.Device, error) {
+	return controlifx.ParseDevice(o.Mac.Serial() + "@" + o.Addr)
+}
+
+// Capture reads the state of the devices with LightGet. Devices that don't respond are left out of the scene and
+// reported in the error, which wraps controlifx.ErrNoResponse.
+func Capture(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device) (scene Scene, err error) {
+	states, err := effects.Snapshot(ctx, conn, devices)
+	if err != nil {
+		return
+	}
+
+	var missing []string
+
+	for _, d := range devices {
+		state, ok := states[d.Mac]
+		if !ok {
+			missing = append(missing, d.String())
+			continue
+		}
+
+		l := Light{
+			Mac:   d.Mac,
+			Label: state.Label,
+			Power: state.Power != controlifx.OffPowerLevel,
+			Color: NewColor(state.Color),
+		}
+		if d.Addr != nil {
+			l.Addr = d.Addr.String()
+		}
+
+		scene.Lights = append(scene.Lights, l)
+	}
+
+	if len(missing) > 0 {
+		err = fmt.Errorf("%w: %s", controlifx.ErrNoResponse, strings.Join(missing, ", "))
+	}
+
+	return
+}
+
+// Apply transitions the lights to the scene over the duration. Only lights whose color or power differ from the
+// scene are sent messages. The lights are looked up by MAC address in the devices, falling back to the address
+// stored in the scene. Apply returns the devices that were changed, and an error listing the lights that couldn't
+// be changed.
+func (o Scene) Apply(ctx context.Context, conn controlifx.Connection, devices []controlifx.Device, duration time.Duration) (changed []controlifx.Device, err error) {
+	byMac := make(map[controlifx.MAC]controlifx.Device, len(devices))
+	for _, d := range devices {
+		byMac[d.Mac] = d
+	}
+
+	var (
+		targets []controlifx.Device
+		lights  []Light
+		errs    []string
+	)
+
+	for _, l := range o.Lights {
+		d, ok := byMac[l.Mac]
+		if !ok {
+			var err error
+			if d, err = l.Device(); err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+		}
+
+		targets = append(targets, d)
+		lights = append(lights, l)
+	}
+
+	current, err := effects.Snapshot(ctx, conn, targets)
+	if err != nil {
+		return
+	}
+
+	for i, l := range lights {
+		d := targets[i]
+
+		if state, ok := current[d.Mac]; ok && l.matches(state) {
+			continue
+		}
+
+		light := controlifx.NewLight(conn, d)
+
+		if err := light.SetColor(ctx, l.Color.HSBK(), duration); err != nil {
+			errs = append(errs, d.String()+": "+err.Error())
+			continue
+		}
+
+		if err := light.SetPower(ctx, l.Power, duration); err != nil {
+			errs = append(errs, d.String()+": "+err.Error())
+			continue
+		}
+
+		changed = append(changed, d)
+	}
+
+	if len(errs) > 0 {
+		err = fmt.Errorf("cannot apply scene: %s", strings.Join(errs, "; "))
+	}
+
+	return
+}
+
+// matches returns whether the state is that of the light, give or take the rounding of the device.
+func (o Light) matches(state controlifx.LightStateLanMessage) bool {
+	c := o.Color.HSBK()
+
+	return o.Power == (state.Power != controlifx.OffPowerLevel) && state.Color.Between(c, c)
+}
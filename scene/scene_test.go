@@ -0,0 +1,77 @@
+package scene
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yath/controlifx"
+)
+
+func TestSceneJSON(t *testing.T) {
+	mac, err := controlifx.ParseMAC("d0:73:d5:01:02:03")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scene := Scene{
+		Name: "evening",
+		Lights: []Light{{
+			Mac:   mac,
+			Addr:  "192.168.1.10:56700",
+			Label: "Desk",
+			Power: true,
+			Color: Color{Hue: 0x5555, Saturation: 0xffff, Brightness: 0x8000, Kelvin: 3500},
+		}},
+	}
+
+	b, err := json.Marshal(scene)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"mac":"d0:73:d5:01:02:03"`) {
+		t.Errorf("expected the MAC address in text form, got '%s'", b)
+	}
+
+	var decoded Scene
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(scene, decoded) {
+		t.Errorf("expected '%+v', got '%+v'", scene, decoded)
+	}
+
+	d, err := decoded.Lights[0].Device()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Mac != mac || d.Addr.String() != "192.168.1.10:56700" {
+		t.Errorf("expected '%v@192.168.1.10:56700', got '%v'", mac, d)
+	}
+}
+
+func TestLightMatches(t *testing.T) {
+	c := controlifx.HSBK{Hue: 0x1000, Saturation: 0x8000, Brightness: 0x6000, Kelvin: 3500}
+	l := Light{Power: true, Color: NewColor(c)}
+
+	tests := []struct {
+		state    controlifx.LightStateLanMessage
+		expected bool
+	}{
+		{controlifx.LightStateLanMessage{Color: c, Power: controlifx.OnPowerLevel}, true},
+		{controlifx.LightStateLanMessage{Color: c, Power: controlifx.OffPowerLevel}, false},
+		// Rounded by the device.
+		{controlifx.LightStateLanMessage{
+			Color: controlifx.HSBK{Hue: 0x0ffe, Saturation: 0x7fff, Brightness: 0x6002, Kelvin: 3500},
+			Power: controlifx.OnPowerLevel,
+		}, true},
+		{controlifx.LightStateLanMessage{Color: controlifx.HSBK{Kelvin: 3500}, Power: controlifx.OnPowerLevel}, false},
+	}
+
+	for i, test := range tests {
+		if v := l.matches(test.state); v != test.expected {
+			t.Errorf("%d: expected '%v', got '%v'", i, test.expected, v)
+		}
+	}
+}
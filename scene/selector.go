@@ -0,0 +1,104 @@
+package scene
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yath/controlifx"
+)
+
+const (
+	// AllSelector selects every device.
+	AllSelector = "all"
+
+	LabelSelector    = "label"
+	GroupSelector    = "group"
+	LocationSelector = "location"
+	IdSelector       = "id"
+)
+
+// Selector selects devices by their label, group, location or serial number, in the syntax of the LIFX HTTP API:
+//
+//	all
+//	label:Desk
+//	group:Kitchen
+//	location:Home
+//	id:d073d5010203
+//
+// Labels, groups and locations are compared case-insensitively.
+type Selector struct {
+	Kind  string
+	Value string
+
+	mac controlifx.MAC
+}
+
+// ParseSelector parses a selector.
+func ParseSelector(s string) (o Selector, err error) {
+	if s == AllSelector {
+		o.Kind = AllSelector
+		return
+	}
+
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		err = fmt.Errorf("invalid selector %q: expected all or kind:value", s)
+		return
+	}
+
+	o.Kind, o.Value = s[:i], s[i+1:]
+	if o.Value == "" {
+		err = fmt.Errorf("invalid selector %q: empty value", s)
+		return
+	}
+
+	switch o.Kind {
+	case LabelSelector, GroupSelector, LocationSelector:
+	case IdSelector:
+		if o.mac, err = controlifx.ParseMAC(o.Value); err != nil {
+			err = fmt.Errorf("invalid selector %q: %v", s, err)
+		}
+	default:
+		err = fmt.Errorf("invalid selector %q: unknown kind %q", s, o.Kind)
+	}
+
+	return
+}
+
+func (o Selector) String() string {
+	if o.Kind == AllSelector {
+		return AllSelector
+	}
+
+	return o.Kind + ":" + o.Value
+}
+
+// Match returns whether the selector selects the device. Label, group and location selectors only match devices
+// whose respective field was received.
+func (o Selector) Match(info controlifx.DeviceInfo) bool {
+	switch o.Kind {
+	case AllSelector:
+		return true
+	case LabelSelector:
+		return strings.EqualFold(info.Label, o.Value)
+	case GroupSelector:
+		return strings.EqualFold(info.Group.Label, o.Value)
+	case LocationSelector:
+		return strings.EqualFold(info.Location.Label, o.Value)
+	case IdSelector:
+		return info.Device.Mac == o.mac
+	}
+
+	return false
+}
+
+// Select returns the devices the selector selects.
+func (o Selector) Select(infos []controlifx.DeviceInfo) (devices []controlifx.Device) {
+	for _, info := range infos {
+		if o.Match(info) {
+			devices = append(devices, info.Device)
+		}
+	}
+
+	return
+}
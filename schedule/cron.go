@@ -0,0 +1,191 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears is how far Next searches for a matching time, which is enough for February 29th.
+const cronSearchYears = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// Cron is a parsed cron expression.
+type Cron struct {
+	expr string
+
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set if the day of month or day of week field is *. Like in Vixie cron, a day matches
+	// if either field matches when both are restricted.
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, monthNames},
+	{"day of week", 0, 7, dayNames},
+}
+
+// ParseCron parses a cron expression with the five fields minute, hour, day of month, month and day of week. Fields
+// are lists of *, values, ranges and steps, such as 1-5 or */15. Months and days of week may be given by their
+// English three-letter names, and 7 is Sunday like 0. The macros @yearly, @monthly, @weekly, @daily and @hourly
+// are accepted as well.
+func ParseCron(expr string) (o Cron, err error) {
+	o.expr = expr
+
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		err = fmt.Errorf("invalid cron expression %q: expected %d fields, got %d", o.expr, len(cronFields),
+			len(fields))
+		return
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, f := range fields {
+		if bits[i], err = cronFields[i].parse(f); err != nil {
+			err = fmt.Errorf("invalid cron expression %q: %v", o.expr, err)
+			return
+		}
+	}
+
+	o.minute, o.hour, o.dom, o.month, o.dow = bits[0], bits[1], bits[2], bits[3], bits[4]
+	o.domStar, o.dowStar = fields[2] == "*", fields[4] == "*"
+
+	// Sunday is both 0 and 7.
+	if o.dow&(1<<7) != 0 {
+		o.dow |= 1
+	}
+
+	return
+}
+
+func (o cronField) parse(s string) (bits uint64, err error) {
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s %q", o.name, part)
+			}
+		}
+
+		lo, hi := o.min, o.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			if lo, err = o.value(bounds[0]); err != nil {
+				return
+			}
+
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = o.value(bounds[1]); err != nil {
+					return
+				}
+			} else if step > 1 {
+				hi = o.max
+			}
+
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range in %s %q", o.name, part)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return
+}
+
+func (o cronField) value(s string) (int, error) {
+	for i, name := range o.names {
+		if strings.EqualFold(s, name) {
+			return i + o.min, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < o.min || v > o.max {
+		return 0, fmt.Errorf("invalid %s %q", o.name, s)
+	}
+
+	return v, nil
+}
+
+func (o Cron) String() string {
+	return o.expr
+}
+
+// Next returns the first time after t that matches the expression, in the location of t, or the zero time if there
+// is none within five years.
+func (o Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(end) {
+		if o.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !o.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if o.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+
+		if o.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (o Cron) matchDay(t time.Time) bool {
+	dom := o.dom&(1<<uint(t.Day())) != 0
+	dow := o.dow&(1<<uint(t.Weekday())) != 0
+
+	if o.domStar || o.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
@@ -0,0 +1,61 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Wednesday.
+	start := time.Date(2020, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2020, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 7 * * *", time.Date(2020, 1, 16, 7, 0, 0, 0, time.UTC)},
+		{"30 6 * * mon-fri", time.Date(2020, 1, 16, 6, 30, 0, 0, time.UTC)},
+		{"0 9 * * sat,sun", time.Date(2020, 1, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2020, 1, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 feb *", time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2020, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Either the day of month or the day of week matches when both are restricted.
+		{"0 0 20 * fri", time.Date(2020, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		c, err := ParseCron(test.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", test.expr, err)
+			continue
+		}
+
+		if v := c.Next(start); !v.Equal(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.expr, test.expected, v)
+		}
+	}
+}
+
+func TestCronNextLocation(t *testing.T) {
+	loc := time.FixedZone("IST", 5*3600+1800)
+	c, _ := ParseCron("0 7 * * *")
+
+	start := time.Date(2020, 1, 15, 6, 40, 0, 0, loc)
+	if v, expected := c.Next(start), time.Date(2020, 1, 15, 7, 0, 0, 0, loc); !v.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, v)
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"5-1 * * * *", "*/0 * * * *", "* * * * xyz"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q): expected an error", expr)
+		}
+	}
+}
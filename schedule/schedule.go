@@ -0,0 +1,299 @@
+// Package schedule applies scenes and runs effects on LIFX devices at times given by cron expressions or by solar
+// events such as sunset, which are computed locally from the latitude and longitude without network access.
+package schedule
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/effects"
+	"github.com/yath/controlifx/scene"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// CatchUpSkip skips the runs of an entry that were missed while the scheduler wasn't running.
+	CatchUpSkip = "skip"
+	// CatchUpLast runs an entry once after the scheduler was down if any of its runs were missed, for example so
+	// that the lights are in the latest scheduled scene.
+	CatchUpLast = "last"
+)
+
+type (
+	// Schedule is a list of entries as stored in YAML or JSON schedule files, for example:
+	//
+	//	latitude: 52.52
+	//	longitude: 13.40
+	//	time_zone: Europe/Berlin
+	//	entries:
+	//	  - name: evening
+	//	    when: sunset-30m
+	//	    scene: evening.yaml
+	//	    catch_up: last
+	//	  - name: wake up
+	//	    when: 30 6 * * mon-fri
+	//	    effect: {name: breathe, selector: group:Bedroom, color: orange, period: 4, cycles: 5}
+	Schedule struct {
+		// Latitude and Longitude are the position for solar events in degrees; north and east are positive.
+		Latitude  float64 `json:"latitude" yaml:"latitude"`
+		Longitude float64 `json:"longitude" yaml:"longitude"`
+		// TimeZone is the IANA name of the time zone of cron expressions and of the days of solar events, or the
+		// local time zone if empty.
+		TimeZone string  `json:"time_zone,omitempty" yaml:"time_zone,omitempty"`
+		Entries  []Entry `json:"entries" yaml:"entries"`
+
+		// dir is the directory of the schedule file, which scene paths are relative to.
+		dir string
+	}
+
+	// Entry applies a scene or runs an effect when its trigger triggers.
+	Entry struct {
+		// Name identifies the entry and must be unique.
+		Name string `json:"name" yaml:"name"`
+		// When is in the syntax of ParseTrigger.
+		When string `json:"when" yaml:"when"`
+		// Scene is the path of a scene file, relative to the schedule file.
+		Scene string `json:"scene,omitempty" yaml:"scene,omitempty"`
+		// Effect is the effect to run if Scene is empty.
+		Effect *Effect `json:"effect,omitempty" yaml:"effect,omitempty"`
+		// CatchUp is CatchUpSkip or CatchUpLast. It's CatchUpSkip if empty.
+		CatchUp string `json:"catch_up,omitempty" yaml:"catch_up,omitempty"`
+	}
+
+	// Effect is an effect run on the devices a selector selects.
+	Effect struct {
+		// Name is the name of one of the effects of the effects package.
+		Name     string `json:"name" yaml:"name"`
+		Selector string `json:"selector" yaml:"selector"`
+		// Color is in the syntax of controlifx.ParseColor.
+		Color string `json:"color" yaml:"color"`
+		// Period is the duration of a cycle in seconds, or effects.DefaultPeriod if 0.
+		Period float64 `json:"period,omitempty" yaml:"period,omitempty"`
+		// Cycles is the number of cycles, or 1 if 0.
+		Cycles float64 `json:"cycles,omitempty" yaml:"cycles,omitempty"`
+	}
+)
+
+// LoadFile reads a schedule file. Files with the .json extension are read as JSON, all others as YAML.
+func LoadFile(name string) (o Schedule, err error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return
+	}
+
+	if isJSON(name) {
+		o, err = LoadJSON(bytes.NewReader(data))
+	} else {
+		o, err = LoadYAML(bytes.NewReader(data))
+	}
+
+	o.dir = filepath.Dir(name)
+
+	return
+}
+
+// LoadYAML reads and validates a schedule in YAML. Unknown fields are an error.
+func LoadYAML(r io.Reader) (o Schedule, err error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	if err = dec.Decode(&o); err != nil {
+		return
+	}
+
+	err = o.Validate()
+
+	return
+}
+
+// LoadJSON reads and validates a schedule in JSON. Unknown fields are an error.
+func LoadJSON(r io.Reader) (o Schedule, err error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(&o); err != nil {
+		return
+	}
+
+	err = o.Validate()
+
+	return
+}
+
+// SaveFile writes the schedule to the file, as JSON if it has the .json extension and as YAML otherwise. The file is
+// replaced atomically.
+func (o Schedule) SaveFile(name string) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	if isJSON(name) {
+		data, err = json.MarshalIndent(o, "", "  ")
+	} else {
+		data, err = yaml.Marshal(o)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeFile(name, data)
+}
+
+// Location returns the location of the time zone.
+func (o Schedule) Location() (*time.Location, error) {
+	if o.TimeZone == "" {
+		return time.Local, nil
+	}
+
+	return time.LoadLocation(o.TimeZone)
+}
+
+// Validate checks the position, the time zone and the entries. The error is a *controlifx.ValidationError.
+func (o Schedule) Validate() error {
+	_, err := o.triggers()
+
+	return err
+}
+
+// triggers validates the schedule and returns the triggers of the entries by name.
+func (o Schedule) triggers() (map[string]Trigger, error) {
+	var problems []string
+
+	problem := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+
+	if o.Latitude < -90 || o.Latitude > 90 {
+		problem("latitude %v is out of range [-90, 90]", o.Latitude)
+	}
+	if o.Longitude < -180 || o.Longitude > 180 {
+		problem("longitude %v is out of range [-180, 180]", o.Longitude)
+	}
+
+	loc, err := o.Location()
+	if err != nil {
+		problem("%v", err)
+		loc = time.Local
+	}
+
+	triggers := make(map[string]Trigger, len(o.Entries))
+
+	for i, e := range o.Entries {
+		for _, p := range e.problems(o.Latitude, o.Longitude, loc, triggers) {
+			problem("entries[%d]: %s", i, p)
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &controlifx.ValidationError{Payload: "schedule", Problems: problems}
+	}
+
+	return triggers, nil
+}
+
+// problems validates the entry and adds its trigger to the triggers.
+func (o Entry) problems(latitude, longitude float64, loc *time.Location, triggers map[string]Trigger) (problems []string) {
+	problem := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+
+	if o.Name == "" {
+		problem("no name")
+	} else if _, ok := triggers[o.Name]; ok {
+		problem("name %q is not unique", o.Name)
+	}
+
+	t, err := ParseTrigger(o.When, latitude, longitude)
+	if err != nil {
+		problem("%v", err)
+	}
+	triggers[o.Name] = locatedTrigger{t, loc}
+
+	switch o.CatchUp {
+	case "", CatchUpSkip, CatchUpLast:
+	default:
+		problem("catch up %q is neither %s nor %s", o.CatchUp, CatchUpSkip, CatchUpLast)
+	}
+
+	switch {
+	case o.Scene != "" && o.Effect != nil:
+		problem("both a scene and an effect")
+	case o.Scene == "" && o.Effect == nil:
+		problem("neither a scene nor an effect")
+	case o.Effect != nil:
+		problems = append(problems, o.Effect.problems()...)
+	}
+
+	return
+}
+
+func (o Effect) problems() (problems []string) {
+	problem := func(format string, a ...interface{}) {
+		problems = append(problems, "effect: "+fmt.Sprintf(format, a...))
+	}
+
+	if _, ok := effects.Lookup(o.Name); !ok {
+		problem("unknown effect %q", o.Name)
+	}
+	if _, err := scene.ParseSelector(o.Selector); err != nil {
+		problem("%v", err)
+	}
+	if _, err := controlifx.ParseColor(o.Color); err != nil {
+		problem("%v", err)
+	}
+	if o.Period < 0 || math.IsNaN(o.Period) {
+		problem("period %v is negative", o.Period)
+	}
+	if o.Cycles < 0 || math.IsNaN(o.Cycles) {
+		problem("cycles %v is negative", o.Cycles)
+	}
+
+	return
+}
+
+// locatedTrigger computes the times of a trigger in a location.
+type locatedTrigger struct {
+	Trigger
+	loc *time.Location
+}
+
+func (o locatedTrigger) Next(t time.Time) time.Time {
+	return o.Trigger.Next(t.In(o.loc))
+}
+
+func isJSON(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".json")
+}
+
+// writeFile replaces the file with the data by writing a temporary file and renaming it.
+func writeFile(name string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".")
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+
+	return err
+}
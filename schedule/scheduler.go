@@ -0,0 +1,398 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yath/controlifx"
+	"github.com/yath/controlifx/effects"
+	"github.com/yath/controlifx/scene"
+)
+
+const (
+	// lateTolerance is how late an entry may run without counting as missed.
+	lateTolerance = time.Minute
+
+	// maxSleep is the longest time the scheduler sleeps before checking the clock again, so that it notices when
+	// the clock jumped, for example after the system was suspended.
+	maxSleep = time.Minute
+
+	// resultBuffer is the number of results buffered before further results are dropped.
+	resultBuffer = 64
+)
+
+type (
+	// Options configure a scheduler.
+	Options struct {
+		// SchedulePath is the file Add and Remove save the schedule to, if set.
+		SchedulePath string
+		// StatePath is the file the time of the last handled run of each entry is saved to, if set. It's needed to
+		// catch up on runs missed while the scheduler wasn't running.
+		StatePath string
+
+		// Run runs an entry. It applies the entry's scene or runs its effect if nil.
+		Run func(ctx context.Context, entry Entry) error
+	}
+
+	// Result is the result of a run of an entry.
+	Result struct {
+		Entry Entry
+		// Time is the time the entry was scheduled to run.
+		Time time.Time
+		// CatchUp is set if the run was missed and is caught up on.
+		CatchUp bool
+		Err     error
+	}
+
+	// Upcoming is the next run of an entry.
+	Upcoming struct {
+		Entry Entry
+		Time  time.Time
+	}
+
+	// Scheduler runs the entries of a schedule when their triggers trigger.
+	Scheduler struct {
+		conn    controlifx.Connection
+		opts    Options
+		results chan Result
+		wake    chan struct{}
+		now     func() time.Time
+
+		mu       sync.Mutex
+		schedule Schedule
+		triggers map[string]Trigger
+		// handled is the time of the last run of each entry that was run or skipped.
+		handled map[string]time.Time
+	}
+
+	// state is the content of the state file.
+	state struct {
+		Handled map[string]time.Time `json:"handled"`
+	}
+)
+
+// New returns a scheduler for the schedule, which must be valid. Runs missed since the times in the state file
+// are caught up on according to the entries' CatchUp when the scheduler runs.
+func New(conn controlifx.Connection, schedule Schedule, opts Options) (*Scheduler, error) {
+	triggers, err := schedule.triggers()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Scheduler{
+		conn:     conn,
+		opts:     opts,
+		results:  make(chan Result, resultBuffer),
+		wake:     make(chan struct{}, 1),
+		now:      time.Now,
+		schedule: schedule,
+		triggers: triggers,
+		handled:  make(map[string]time.Time),
+	}
+
+	if opts.StatePath != "" {
+		data, err := ioutil.ReadFile(opts.StatePath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		if err == nil {
+			var s state
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, fmt.Errorf("invalid state file %s: %v", opts.StatePath, err)
+			}
+
+			for name, t := range s.Handled {
+				if _, ok := triggers[name]; ok {
+					o.handled[name] = t
+				}
+			}
+		}
+	}
+
+	// Entries without a state start now.
+	now := o.now()
+	for name := range triggers {
+		if _, ok := o.handled[name]; !ok {
+			o.handled[name] = now
+		}
+	}
+
+	if o.opts.Run == nil {
+		o.opts.Run = o.run
+	}
+
+	return o, nil
+}
+
+// Results returns the channel the results of runs are sent on. Results are dropped while the channel is full, so it
+// should be drained continuously.
+func (o *Scheduler) Results() <-chan Result {
+	return o.results
+}
+
+// Schedule returns the schedule.
+func (o *Scheduler) Schedule() Schedule {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	s := o.schedule
+	s.Entries = append([]Entry(nil), s.Entries...)
+
+	return s
+}
+
+// Add adds the entry, or replaces the entry with the same name, and saves the schedule to SchedulePath. Its runs
+// start now.
+func (o *Scheduler) Add(entry Entry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	s := o.schedule
+	s.Entries = nil
+	for _, e := range o.schedule.Entries {
+		if e.Name != entry.Name {
+			s.Entries = append(s.Entries, e)
+		}
+	}
+	s.Entries = append(s.Entries, entry)
+
+	if err := o.replace(s); err != nil {
+		return err
+	}
+
+	o.handled[entry.Name] = o.now()
+
+	return nil
+}
+
+// Remove removes the entry with the name and saves the schedule to SchedulePath.
+func (o *Scheduler) Remove(name string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	s := o.schedule
+	s.Entries = nil
+	for _, e := range o.schedule.Entries {
+		if e.Name != name {
+			s.Entries = append(s.Entries, e)
+		}
+	}
+
+	if len(s.Entries) == len(o.schedule.Entries) {
+		return fmt.Errorf("no entry %q", name)
+	}
+
+	if err := o.replace(s); err != nil {
+		return err
+	}
+
+	delete(o.handled, name)
+
+	return nil
+}
+
+// replace validates and saves the schedule and makes it the current one.
+func (o *Scheduler) replace(s Schedule) error {
+	triggers, err := s.triggers()
+	if err != nil {
+		return err
+	}
+
+	if o.opts.SchedulePath != "" {
+		if err := s.SaveFile(o.opts.SchedulePath); err != nil {
+			return err
+		}
+	}
+
+	o.schedule, o.triggers = s, triggers
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Upcoming returns the next run of each entry that has one, ordered by time.
+func (o *Scheduler) Upcoming() []Upcoming {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var upcoming []Upcoming
+	for _, e := range o.schedule.Entries {
+		if t := o.triggers[e.Name].Next(o.handled[e.Name]); !t.IsZero() {
+			upcoming = append(upcoming, Upcoming{Entry: e, Time: t})
+		}
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].Time.Before(upcoming[j].Time)
+	})
+
+	return upcoming
+}
+
+// Run runs the entries when they're due until the context is done, and returns the context's error. Entries run one
+// after another.
+func (o *Scheduler) Run(ctx context.Context) error {
+	for {
+		for _, r := range o.due(o.now()) {
+			r.Err = o.opts.Run(ctx, r.Entry)
+
+			select {
+			case o.results <- r:
+			default:
+			}
+		}
+
+		sleep := maxSleep
+		if upcoming := o.Upcoming(); len(upcoming) > 0 {
+			if d := upcoming[0].Time.Sub(o.now()); d < sleep {
+				sleep = d
+			}
+		}
+
+		timer := time.NewTimer(sleep)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-o.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// due returns the runs that are due at the time and marks them as handled. Of each entry only the latest run is
+// returned. If it's late, it was missed and is only returned if the entry catches up on missed runs.
+func (o *Scheduler) due(now time.Time) (due []Result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	changed := false
+
+	for _, e := range o.schedule.Entries {
+		trigger := o.triggers[e.Name]
+
+		t := trigger.Next(o.handled[e.Name])
+		if t.IsZero() || t.After(now) {
+			continue
+		}
+
+		for {
+			next := trigger.Next(t)
+			if next.IsZero() || next.After(now) {
+				break
+			}
+
+			t = next
+		}
+
+		o.handled[e.Name] = t
+		changed = true
+
+		missed := now.Sub(t) > lateTolerance
+		if !missed || e.CatchUp == CatchUpLast {
+			due = append(due, Result{Entry: e, Time: t, CatchUp: missed})
+		}
+	}
+
+	if changed && o.opts.StatePath != "" {
+		// A failure to save only means that missed runs might be caught up on twice.
+		o.saveState()
+	}
+
+	return
+}
+
+func (o *Scheduler) saveState() error {
+	data, err := json.MarshalIndent(state{Handled: o.handled}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(o.opts.StatePath, data)
+}
+
+// run applies the entry's scene or runs its effect.
+func (o *Scheduler) run(ctx context.Context, entry Entry) error {
+	if entry.Scene != "" {
+		path := entry.Scene
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(o.Schedule().dir, path)
+		}
+
+		file, err := scene.LoadFile(path)
+		if err != nil {
+			return err
+		}
+
+		_, err = scene.Apply(ctx, o.conn, file)
+
+		return err
+	}
+
+	if entry.Effect == nil {
+		return errors.New("neither a scene nor an effect")
+	}
+
+	return entry.Effect.run(ctx, o.conn)
+}
+
+func (o Effect) run(ctx context.Context, conn controlifx.Connection) error {
+	effect, ok := effects.Lookup(o.Name)
+	if !ok {
+		return fmt.Errorf("unknown effect %q", o.Name)
+	}
+
+	selector, err := scene.ParseSelector(o.Selector)
+	if err != nil {
+		return err
+	}
+
+	color, err := controlifx.ParseColor(o.Color)
+	if err != nil {
+		return err
+	}
+
+	infos, err := scene.Discover(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	devices := selector.Select(infos)
+	if len(devices) == 0 {
+		return fmt.Errorf("selector %s selects no devices", selector)
+	}
+
+	cycles := o.Cycles
+	if cycles == 0 {
+		cycles = 1
+	}
+
+	run, err := effects.Start(ctx, conn, devices, effect, effects.Options{
+		Color: color.Apply(controlifx.HSBK{
+			Brightness: 0xffff,
+			Kelvin:     controlifx.DefaultKelvin,
+		}),
+		Period: time.Duration(o.Period * float64(time.Second)),
+		Cycles: cycles,
+	})
+	if err != nil {
+		return err
+	}
+
+	return run.Wait()
+}
@@ -0,0 +1,152 @@
+package schedule
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+)
+
+const testSchedule = `
+latitude: 52.52
+longitude: 13.40
+time_zone: UTC
+entries:
+  - name: evening
+    when: 0 18 * * *
+    scene: evening.yaml
+    catch_up: last
+  - name: reminder
+    when: 0 * * * *
+    effect: {name: pulse, selector: all, color: red}
+`
+
+func TestLoadYAML(t *testing.T) {
+	s, err := LoadYAML(strings.NewReader(testSchedule))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Entries) != 2 || s.Entries[0].CatchUp != CatchUpLast || s.Entries[1].Effect.Name != "pulse" {
+		t.Errorf("expected two entries catching up the first and pulsing the second, got '%+v'", s)
+	}
+
+	invalid := Schedule{
+		Latitude: 100,
+		Entries: []Entry{
+			{Name: "a", When: "0 25 * * *", Scene: "a.yaml"},
+			{Name: "a", When: "sunset", Scene: "a.yaml", Effect: &Effect{}},
+			{When: "sunrise", CatchUp: "all"},
+		},
+	}
+
+	err = invalid.Validate()
+	verr, ok := err.(*controlifx.ValidationError)
+	if !ok {
+		t.Fatalf("expected *controlifx.ValidationError, got %T", err)
+	}
+	if len(verr.Problems) != 7 {
+		t.Errorf("expected '7' problems, got '%q'", verr.Problems)
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	s, err := LoadYAML(strings.NewReader(testSchedule))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "schedule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := Options{
+		StatePath: filepath.Join(dir, "state.json"),
+		Run: func(context.Context, Entry) error {
+			return nil
+		},
+	}
+
+	day1 := time.Date(2020, 1, 1, 17, 30, 0, 0, time.UTC)
+
+	o, err := New(controlifx.Connection{}, s, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.handled = map[string]time.Time{"evening": day1, "reminder": day1}
+
+	// On time.
+	due := o.due(day1.Add(30*time.Minute + 10*time.Second))
+	if len(due) != 2 || due[0].CatchUp || due[1].CatchUp {
+		t.Fatalf("expected both entries on time, got '%+v'", due)
+	}
+
+	// Nothing due again within the same minute.
+	if due := o.due(day1.Add(30*time.Minute + 20*time.Second)); len(due) != 0 {
+		t.Fatalf("expected nothing, got '%+v'", due)
+	}
+
+	// The scheduler was down for two days. A new scheduler reads the state.
+	o, err = New(controlifx.Connection{}, s, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day3 := day1.Add(48 * time.Hour)
+	due = o.due(day3)
+	if len(due) != 1 || due[0].Entry.Name != "evening" || !due[0].CatchUp ||
+		!due[0].Time.Equal(time.Date(2020, 1, 2, 18, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the last missed evening, got '%+v'", due)
+	}
+
+	// The missed reminders were skipped rather than run late.
+	for _, next := range o.Upcoming() {
+		if !next.Time.Equal(day3.Add(30 * time.Minute)) {
+			t.Errorf("Upcoming(): %s at %v, expected %v", next.Entry.Name, next.Time, day3.Add(30*time.Minute))
+		}
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schedule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "schedule.json")
+
+	o, err := New(controlifx.Connection{}, Schedule{}, Options{SchedulePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Add(Entry{Name: "a", When: "bad", Scene: "a.yaml"}); err == nil {
+		t.Error("Add() with an invalid entry succeeded")
+	}
+
+	if err := o.Add(Entry{Name: "a", When: "sunset", Scene: "a.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Add(Entry{Name: "b", When: "@daily", Scene: "b.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Entries) != 1 || s.Entries[0].Name != "b" {
+		t.Errorf("expected only the entry 'b', got '%+v'", s)
+	}
+}
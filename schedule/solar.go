@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SolarEvent is a position of the sun relative to the horizon during a day.
+type SolarEvent int
+
+const (
+	CivilDawn SolarEvent = iota
+	Sunrise
+	Sunset
+	CivilDusk
+)
+
+var solarEventNames = map[SolarEvent]string{
+	CivilDawn: "civil_dawn",
+	Sunrise:   "sunrise",
+	Sunset:    "sunset",
+	CivilDusk: "civil_dusk",
+}
+
+const (
+	// sunriseElevation is the elevation of the center of the sun at sunrise and sunset in degrees, accounting for
+	// atmospheric refraction and the radius of the sun.
+	sunriseElevation = -0.833
+
+	// civilElevation is the elevation of the center of the sun at civil dawn and dusk in degrees.
+	civilElevation = -6
+
+	// obliquity is the axial tilt of the earth in degrees.
+	obliquity = 23.44
+)
+
+// j2000 is the epoch of the sunrise equation.
+var j2000 = time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// ParseSolarEvent parses the name of a solar event: sunrise, sunset, civil_dawn or civil_dusk.
+func ParseSolarEvent(s string) (SolarEvent, error) {
+	for e, name := range solarEventNames {
+		if s == name {
+			return e, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown solar event %q", s)
+}
+
+func (o SolarEvent) String() string {
+	if name, ok := solarEventNames[o]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// Time returns the time of the event on the date of day in its location, at the latitude and longitude in degrees
+// (north and east are positive). It returns false if the sun doesn't reach the event's elevation that day, as in
+// polar days and nights. The time is accurate to about a minute.
+func (o SolarEvent) Time(day time.Time, latitude, longitude float64) (time.Time, bool) {
+	rad := math.Pi / 180
+
+	// Mean solar noon at the longitude, in days since the epoch.
+	year, month, date := day.Date()
+	noon := time.Date(year, month, date, 12, 0, 0, 0, time.UTC).Add(-time.Duration(longitude / 15 * float64(time.Hour)))
+	j := noon.Sub(j2000).Hours() / 24
+
+	// Mean anomaly, equation of the center and ecliptic longitude of the sun.
+	m := math.Mod(357.5291+0.98560028*j, 360)
+	c := 1.9148*math.Sin(m*rad) + 0.02*math.Sin(2*m*rad) + 0.0003*math.Sin(3*m*rad)
+	lambda := math.Mod(m+c+180+102.9372, 360)
+
+	// Solar transit and declination of the sun.
+	transit := j + 0.0053*math.Sin(m*rad) - 0.0069*math.Sin(2*lambda*rad)
+	sinDecl := math.Sin(lambda*rad) * math.Sin(obliquity*rad)
+	cosDecl := math.Cos(math.Asin(sinDecl))
+
+	elevation := sunriseElevation
+	if o == CivilDawn || o == CivilDusk {
+		elevation = civilElevation
+	}
+
+	// Hour angle of the event.
+	cosOmega := (math.Sin(elevation*rad) - math.Sin(latitude*rad)*sinDecl) / (math.Cos(latitude*rad) * cosDecl)
+	if cosOmega < -1 || cosOmega > 1 {
+		return time.Time{}, false
+	}
+	omega := math.Acos(cosOmega) / rad
+
+	t := transit + omega/360
+	if o == CivilDawn || o == Sunrise {
+		t = transit - omega/360
+	}
+
+	return j2000.Add(time.Duration(t * 24 * float64(time.Hour))).Round(time.Second).In(day.Location()), true
+}
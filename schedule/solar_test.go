@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSolarEventTime(t *testing.T) {
+	london := func(day time.Time, e SolarEvent) (time.Time, bool) {
+		return e.Time(day, 51.5074, -0.1278)
+	}
+	midsummer := time.Date(2020, 6, 21, 0, 0, 0, 0, time.UTC)
+
+	// Times from the NOAA solar calculator.
+	tests := []struct {
+		event    SolarEvent
+		expected time.Time
+	}{
+		{Sunrise, time.Date(2020, 6, 21, 3, 43, 0, 0, time.UTC)},
+		{Sunset, time.Date(2020, 6, 21, 20, 21, 0, 0, time.UTC)},
+		{CivilDawn, time.Date(2020, 6, 21, 2, 56, 0, 0, time.UTC)},
+		{CivilDusk, time.Date(2020, 6, 21, 21, 8, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		v, ok := london(midsummer, test.event)
+		if !ok {
+			t.Errorf("%v: expected a time", test.event)
+			continue
+		}
+
+		if d := v.Sub(test.expected); d < -2*time.Minute || d > 2*time.Minute {
+			t.Errorf("%v: expected %v, got %v", test.event, test.expected, v)
+		}
+	}
+
+	// The sun doesn't set at midsummer in Tromsø.
+	if v, ok := Sunset.Time(midsummer, 69.6492, 18.9553); ok {
+		t.Errorf("Tromsø: expected no sunset, got %v", v)
+	}
+}
+
+func TestParseTrigger(t *testing.T) {
+	tests := []struct {
+		when     string
+		expected string
+	}{
+		{"sunrise", "sunrise"},
+		{"sunset-30m", "sunset-30m0s"},
+		{"civil_dusk+1h15m", "civil_dusk+1h15m0s"},
+		{"0 7 * * *", "0 7 * * *"},
+	}
+
+	for _, test := range tests {
+		trigger, err := ParseTrigger(test.when, 51.5, 0)
+		if err != nil {
+			t.Errorf("ParseTrigger(%q): %v", test.when, err)
+			continue
+		}
+
+		if s := trigger.String(); s != test.expected {
+			t.Errorf("ParseTrigger(%q): expected %s, got %s", test.when, test.expected, s)
+		}
+	}
+
+	for _, when := range []string{"sunset30m", "sunset-30", "sundown"} {
+		if _, err := ParseTrigger(when, 0, 0); err == nil {
+			t.Errorf("ParseTrigger(%q): expected an error", when)
+		}
+	}
+}
+
+func TestSolarTriggerNext(t *testing.T) {
+	trigger := SolarTrigger{Event: Sunset, Offset: -30 * time.Minute, Latitude: 51.5074, Longitude: -0.1278}
+
+	// Before the event on the same day, and after it.
+	for _, start := range []time.Time{
+		time.Date(2020, 6, 21, 12, 0, 0, 0, time.UTC),
+		time.Date(2020, 6, 21, 20, 0, 0, 0, time.UTC),
+	} {
+		v := trigger.Next(start)
+		sunset, _ := Sunset.Time(v, trigger.Latitude, trigger.Longitude)
+
+		if !v.After(start) || v.Sub(start) > 24*time.Hour || !v.Equal(sunset.Add(trigger.Offset)) {
+			t.Errorf("Next(%v): got %v", start, v)
+		}
+	}
+}
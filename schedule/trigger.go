@@ -0,0 +1,90 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// solarSearchDays is how many days Next searches for a solar event, which is enough to get past a polar night.
+const solarSearchDays = 370
+
+// Trigger gives the times at which an entry runs.
+type Trigger interface {
+	// Next returns the first time after t at which the entry runs, or the zero time if there is none.
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// SolarTrigger triggers at a solar event each day, moved by an offset.
+type SolarTrigger struct {
+	Event  SolarEvent
+	Offset time.Duration
+
+	// Latitude and Longitude are in degrees; north and east are positive.
+	Latitude, Longitude float64
+}
+
+// Next returns the first time after t at which the trigger triggers, or the zero time if the solar event doesn't
+// happen within a year, such as close to the poles. Days are in the location of t.
+func (o SolarTrigger) Next(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	// Start a day early, since a negative offset can move the event to the day before.
+	for i := -1; i < solarSearchDays; i++ {
+		date := time.Date(year, month, day+i, 12, 0, 0, 0, t.Location())
+
+		if event, ok := o.Event.Time(date, o.Latitude, o.Longitude); ok {
+			if event = event.Add(o.Offset); event.After(t) {
+				return event
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+func (o SolarTrigger) String() string {
+	switch {
+	case o.Offset > 0:
+		return o.Event.String() + "+" + o.Offset.String()
+	case o.Offset < 0:
+		return o.Event.String() + o.Offset.String()
+	}
+
+	return o.Event.String()
+}
+
+// ParseTrigger parses when an entry runs: either a solar event with an optional offset, such as sunrise,
+// sunset-30m or civil_dusk+1h15m, or a cron expression as accepted by ParseCron. The latitude and longitude are
+// those of solar events.
+func ParseTrigger(when string, latitude, longitude float64) (Trigger, error) {
+	when = strings.TrimSpace(when)
+
+	for event, name := range solarEventNames {
+		if !strings.HasPrefix(when, name) {
+			continue
+		}
+
+		t := SolarTrigger{
+			Event:     event,
+			Latitude:  latitude,
+			Longitude: longitude,
+		}
+
+		if offset := when[len(name):]; offset != "" {
+			if offset[0] != '+' && offset[0] != '-' {
+				return nil, fmt.Errorf("invalid trigger %q: expected + or - after %s", when, name)
+			}
+
+			var err error
+			if t.Offset, err = time.ParseDuration(offset); err != nil {
+				return nil, fmt.Errorf("invalid trigger %q: %v", when, err)
+			}
+		}
+
+		return t, nil
+	}
+
+	return ParseCron(when)
+}
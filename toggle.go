@@ -0,0 +1,80 @@
+package controlifx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ToggleRule decides whether Toggle turns a set of devices on or off, given how many of them are on.
+type ToggleRule int
+
+const (
+	// AnyOnToggle turns the devices off if any of them is on, like a room switch.
+	AnyOnToggle ToggleRule = iota
+	// MajorityToggle turns the devices off if at least half of them are on.
+	MajorityToggle
+)
+
+// on returns whether devices of which the number are on should be turned on.
+func (o ToggleRule) on(on, total int) bool {
+	if o == MajorityToggle {
+		return on*2 < total
+	}
+
+	return on == 0
+}
+
+// Toggle turns the devices on or off together. It asks all devices for their power level, decides by the rule
+// whether to turn them all on or off, and sends that to all of them, waiting for their acknowledgements. Devices
+// that didn't report their power level don't count toward the rule but are still switched. Toggle returns whether
+// the devices were turned on, and an error wrapping ErrNoResponse listing the devices that didn't acknowledge.
+func (o Connection) Toggle(ctx context.Context, devices []Device, rule ToggleRule, duration time.Duration) (on bool, err error) {
+	recMsgs, err := o.SendToAndGetContext(ctx, LightGetPower(), devices, TypeFilter(LightStatePowerType))
+	if err != nil {
+		return
+	}
+
+	if len(recMsgs) == 0 {
+		return false, ErrNoResponse
+	}
+
+	var n int
+	for _, recMsg := range recMsgs {
+		if recMsg.Payload.(*LightStatePowerLanMessage).Level != OffPowerLevel {
+			n++
+		}
+	}
+
+	on = rule.on(n, len(recMsgs))
+
+	var level uint16 = OffPowerLevel
+	if on {
+		level = OnPowerLevel
+	}
+
+	msg := LightSetPower(LightSetPowerLanMessage{
+		Level:    level,
+		Duration: DurationToMs(duration),
+	})
+	msg.Header.FrameAddress.AckRequired = true
+
+	acks, err := o.SendToAndGetContext(ctx, msg, devices, TypeFilter(AcknowledgementType))
+	if err != nil {
+		return
+	}
+
+	var missing []string
+	for _, d := range devices {
+		if _, ok := acks[d]; !ok {
+			missing = append(missing, d.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		err = fmt.Errorf("%w: %s", ErrNoResponse, strings.Join(missing, ", "))
+	}
+
+	return
+}
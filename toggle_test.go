@@ -0,0 +1,90 @@
+package controlifx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yath/controlifx/internal/fakelight"
+)
+
+func TestToggleRule(t *testing.T) {
+	tests := []struct {
+		rule      ToggleRule
+		on, total int
+		expected  bool
+	}{
+		{AnyOnToggle, 0, 3, true},
+		{AnyOnToggle, 1, 3, false},
+		{AnyOnToggle, 3, 3, false},
+		{MajorityToggle, 0, 3, true},
+		{MajorityToggle, 1, 3, true},
+		{MajorityToggle, 2, 3, false},
+		{MajorityToggle, 2, 4, false},
+		{MajorityToggle, 1, 4, true},
+	}
+
+	for _, test := range tests {
+		if v := test.rule.on(test.on, test.total); v != test.expected {
+			t.Errorf("rule %d with %d of %d on: expected '%v', got '%v'", test.rule, test.on, test.total,
+				test.expected, v)
+		}
+	}
+}
+
+func TestConnection_Toggle(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	var (
+		lights  []*fakelight.Light
+		devices []Device
+	)
+	for mac := uint64(1); mac <= 5; mac++ {
+		f, d := testLight(t, mac)
+		defer f.Close()
+
+		lights, devices = append(lights, f), append(devices, d)
+	}
+
+	// 1 is on and 2, 3 and 5 are off. 4 doesn't report its power, so it doesn't count, and 5 doesn't acknowledge.
+	lights[0].Update(func(s *fakelight.State) { s.Power = OnPowerLevel })
+	lights[3].Drop(LightGetPowerType, -1)
+	lights[4].Drop(LightSetPowerType, -1)
+
+	on, err := conn.Toggle(context.Background(), devices, MajorityToggle, 0)
+	if !on {
+		t.Errorf("expected '%v', got '%v'", true, on)
+	}
+	if !errors.Is(err, ErrNoResponse) || !strings.Contains(err.Error(), devices[4].String()) {
+		t.Errorf("expected '%v' listing '%v', got '%v'", ErrNoResponse, devices[4], err)
+	}
+
+	for _, f := range lights[:4] {
+		if p := f.State().Power; p != OnPowerLevel {
+			t.Errorf("%x: expected '%#x', got '%#x'", f.Mac, OnPowerLevel, p)
+		}
+		// The decision is made once and sent to all at once.
+		if n := f.Count(LightSetPowerType); n != 1 {
+			t.Errorf("%x: expected '1' power message, got '%d'", f.Mac, n)
+		}
+	}
+	if p := lights[4].State().Power; p != OffPowerLevel {
+		t.Errorf("expected '%#x', got '%#x'", OffPowerLevel, p)
+	}
+
+	// Now that they're on, they're all turned off.
+	on, err = conn.Toggle(context.Background(), devices[:4], AnyOnToggle, 0)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if on {
+		t.Errorf("expected '%v', got '%v'", false, on)
+	}
+	for _, f := range lights[:4] {
+		if p := f.State().Power; p != OffPowerLevel {
+			t.Errorf("%x: expected '%#x', got '%#x'", f.Mac, OffPowerLevel, p)
+		}
+	}
+}
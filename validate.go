@@ -0,0 +1,137 @@
+package controlifx
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator is implemented by payloads that can check their fields before they're sent. Connections in strict mode
+// refuse to send payloads that fail validation.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError lists the problems Validate found with a payload.
+type ValidationError struct {
+	Payload  string
+	Problems []string
+}
+
+func (o *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", o.Payload, strings.Join(o.Problems, "; "))
+}
+
+type validation ValidationError
+
+func (o *validation) check(ok bool, format string, a ...interface{}) {
+	if !ok {
+		o.Problems = append(o.Problems, fmt.Sprintf(format, a...))
+	}
+}
+
+func (o *validation) checkLabel(field, label string) {
+	o.check(len(label) <= MaxLabelSize, "%s is %d bytes long and would be truncated to %d bytes",
+		field, len(label), MaxLabelSize)
+	o.check(utf8.ValidString(label), "%s is not valid UTF-8", field)
+	o.check(strings.IndexByte(label, 0) < 0, "%s contains a NUL byte and would be truncated", field)
+}
+
+func (o *validation) checkPowerLevel(level uint16) {
+	o.check(level == OffPowerLevel || level == OnPowerLevel, "level %d is neither %d nor %d",
+		level, OffPowerLevel, OnPowerLevel)
+}
+
+func (o *validation) checkColor(field string, c HSBK) {
+	o.check(c.Kelvin >= MinKelvin && c.Kelvin <= MaxKelvin, "%s kelvin %d is out of range [%d, %d]",
+		field, c.Kelvin, MinKelvin, MaxKelvin)
+}
+
+func (o *validation) checkWaveform(waveform uint8, cycles float32) {
+	o.check(waveform <= PulseWaveform, "waveform %d is unknown", waveform)
+	o.check(cycles > 0, "cycles %v is not positive", cycles)
+}
+
+func (o *validation) err() error {
+	if len(o.Problems) == 0 {
+		return nil
+	}
+
+	return (*ValidationError)(o)
+}
+
+func (o SetPowerLanMessage) Validate() error {
+	v := validation{Payload: "SetPower"}
+	v.checkPowerLevel(o.Level)
+
+	return v.err()
+}
+
+func (o SetLabelLanMessage) Validate() error {
+	v := validation{Payload: "SetLabel"}
+	v.checkLabel("label", o.Label)
+
+	return v.err()
+}
+
+func (o SetOwnerLanMessage) Validate() error {
+	v := validation{Payload: "SetOwner"}
+	v.checkLabel("label", o.Label)
+
+	return v.err()
+}
+
+func (o EchoRequestLanMessage) Validate() error {
+	return nil
+}
+
+func (o HSBK) Validate() error {
+	v := validation{Payload: "HSBK"}
+	v.checkColor("color", o)
+
+	return v.err()
+}
+
+func (o LightSetColorLanMessage) Validate() error {
+	v := validation{Payload: "LightSetColor"}
+	v.checkColor("color", o.Color)
+
+	return v.err()
+}
+
+func (o LightSetPowerLanMessage) Validate() error {
+	v := validation{Payload: "LightSetPower"}
+	v.checkPowerLevel(o.Level)
+
+	return v.err()
+}
+
+func (o LightSetWaveformLanMessage) Validate() error {
+	v := validation{Payload: "LightSetWaveform"}
+	v.checkColor("color", o.Color)
+	v.checkWaveform(o.Waveform, o.Cycles)
+
+	return v.err()
+}
+
+func (o LightSetWaveformOptionalLanMessage) Validate() error {
+	v := validation{Payload: "LightSetWaveformOptional"}
+	if o.SetKelvin {
+		v.checkColor("color", o.Color)
+	}
+	v.checkWaveform(o.Waveform, o.Cycles)
+
+	return v.err()
+}
+
+func (o LightSetDimAbsoluteLanMessage) Validate() error {
+	return nil
+}
+
+func (o LightSetDimRelativeLanMessage) Validate() error {
+	v := validation{Payload: "LightSetDimRelative"}
+	v.check(o.Brightness >= -0xffff && o.Brightness <= 0xffff, "brightness %d is out of range [%d, %d]",
+		o.Brightness, -0xffff, 0xffff)
+
+	return v.err()
+}
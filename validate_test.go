@@ -0,0 +1,82 @@
+package controlifx
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []Validator{
+		SetPowerLanMessage{Level: OffPowerLevel},
+		SetPowerLanMessage{Level: OnPowerLevel},
+		SetLabelLanMessage{Label: "Kitchen"},
+		SetLabelLanMessage{Label: strings.Repeat("ä", 16)},
+		SetOwnerLanMessage{Label: "Owner"},
+		EchoRequestLanMessage{},
+		LightSetColorLanMessage{Color: HSBK{Kelvin: 3500}},
+		LightSetPowerLanMessage{Level: OnPowerLevel, Duration: 1000},
+		LightSetDimAbsoluteLanMessage{Brightness: 0xffff},
+		LightSetDimRelativeLanMessage{Brightness: -0xffff},
+	}
+
+	for _, p := range valid {
+		if err := p.Validate(); err != nil {
+			t.Errorf("%#v: unexpected error: %v", p, err)
+		}
+	}
+}
+
+func TestValidate2(t *testing.T) {
+	invalid := map[Validator]string{
+		SetPowerLanMessage{Level: 1}:                         "invalid SetPower: level 1 is neither 0 nor 65535",
+		SetLabelLanMessage{Label: strings.Repeat("a", 33)}:   "invalid SetLabel: label is 33 bytes long and would be truncated to 32 bytes",
+		SetLabelLanMessage{Label: "\xff"}:                    "invalid SetLabel: label is not valid UTF-8",
+		SetLabelLanMessage{Label: "a\x00b"}:                  "invalid SetLabel: label contains a NUL byte and would be truncated",
+		SetOwnerLanMessage{Label: strings.Repeat("ä", 17)}:   "invalid SetOwner: label is 34 bytes long and would be truncated to 32 bytes",
+		LightSetColorLanMessage{}:                            "invalid LightSetColor: color kelvin 0 is out of range [1500, 9000]",
+		LightSetPowerLanMessage{Level: 0x8000}:               "invalid LightSetPower: level 32768 is neither 0 nor 65535",
+		LightSetColorLanMessage{Color: HSBK{Kelvin: 0xffff}}: "invalid LightSetColor: color kelvin 65535 is out of range [1500, 9000]",
+		LightSetDimRelativeLanMessage{Brightness: 0x10000}:   "invalid LightSetDimRelative: brightness 65536 is out of range [-65535, 65535]",
+	}
+
+	for p, expected := range invalid {
+		err := p.Validate()
+		if err == nil {
+			t.Errorf("%#v: expected error", p)
+			continue
+		}
+
+		if _, ok := err.(*ValidationError); !ok {
+			t.Errorf("%#v: expected *ValidationError, got %T", p, err)
+		}
+
+		if expected != err.Error() {
+			t.Errorf("expected '%s', got '%s'", expected, err)
+		}
+	}
+}
+
+func TestConnection_Strict(t *testing.T) {
+	conn := testConnection(t)
+	defer conn.Close()
+
+	f, d := testLight(t, 1)
+	defer f.Close()
+
+	msg := SetPower(SetPowerLanMessage{Level: 1})
+	msg.Header.FrameAddress.AckRequired = true
+
+	if _, err := conn.SendToAndGetContext(context.Background(), msg, []Device{d}, nil); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	conn.Strict = true
+	if _, err := conn.SendToAndGetContext(context.Background(), msg, []Device{d}, nil); err == nil {
+		t.Error("expected error")
+	}
+
+	if n := f.Count(SetPowerType); n != 1 {
+		t.Errorf("expected '1' message, got '%d'", n)
+	}
+}
@@ -0,0 +1,62 @@
+package watch
+
+import (
+	"time"
+
+	"github.com/yath/controlifx"
+)
+
+const (
+	// clockTolerance is how far behind the expected uptime a device's uptime may be without counting as a reboot, to
+	// allow for the clock of the device and the latency of the poll.
+	clockTolerance = 2 * time.Second
+
+	// driftTolerance is the fraction of the time between two polls that the uptime may fall behind in addition to
+	// clockTolerance.
+	driftTolerance = 0.01
+)
+
+// Tracker follows the uptime of a single device across polls.
+type Tracker struct {
+	// Info is the last info received from the device.
+	Info controlifx.StateInfoLanMessage
+	// LastSeen is the time Info was received, or zero if no info was received yet.
+	LastSeen time.Time
+}
+
+// Observe records the info received from the device at the time and returns a Rebooted or PowerLost event if the
+// device was restarted since the last observation. The device was restarted if its uptime is less than its last
+// uptime plus the time that passed, which also catches restarts that happened long enough ago for the uptime to
+// have grown past the last one. It lost power if its downtime changed; the outage is then its downtime, and
+// otherwise the time its uptime is missing.
+func (o *Tracker) Observe(now time.Time, info controlifx.StateInfoLanMessage) (e Event, ok bool) {
+	prev, lastSeen := o.Info, o.LastSeen
+	o.Info, o.LastSeen = info, now
+
+	if lastSeen.IsZero() {
+		return
+	}
+
+	elapsed := now.Sub(lastSeen)
+	expected := time.Duration(prev.Uptime) + elapsed
+	uptime := time.Duration(info.Uptime)
+
+	if uptime+clockTolerance+time.Duration(driftTolerance*float64(elapsed)) >= expected {
+		return
+	}
+
+	e = Event{
+		Kind:   Rebooted,
+		Time:   now,
+		Uptime: uptime,
+		Outage: expected - uptime,
+		Info:   info,
+	}
+
+	if info.Downtime != 0 && info.Downtime != prev.Downtime {
+		e.Kind = PowerLost
+		e.Outage = time.Duration(info.Downtime)
+	}
+
+	return e, true
+}
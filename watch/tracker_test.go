@@ -0,0 +1,59 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yath/controlifx"
+)
+
+func info(uptime, downtime time.Duration) controlifx.StateInfoLanMessage {
+	return controlifx.StateInfoLanMessage{Uptime: uint64(uptime), Downtime: uint64(downtime)}
+}
+
+func TestTracker(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		prev    controlifx.StateInfoLanMessage
+		cur     controlifx.StateInfoLanMessage
+		ok      bool
+		kind    EventKind
+		outage  time.Duration
+	}{
+		{"running", time.Minute, info(time.Hour, 0), info(time.Hour+time.Minute, 0), false, 0, 0},
+		{"clock skew", time.Minute, info(time.Hour, 0), info(time.Hour+time.Minute-time.Second, 0), false, 0, 0},
+		{"reboot", time.Minute, info(time.Hour, 0), info(20*time.Second, 0), true, Rebooted, time.Hour + 40*time.Second},
+		{"power lost", time.Minute, info(time.Hour, 0), info(20*time.Second, 35*time.Second), true, PowerLost, 35 * time.Second},
+		{"same downtime", time.Minute, info(time.Hour, 5*time.Second), info(20*time.Second, 5*time.Second), true, Rebooted, time.Hour + 40*time.Second},
+		{"uptime grew past", time.Hour, info(time.Minute, 0), info(30*time.Minute, 10*time.Minute), true, PowerLost, 10 * time.Minute},
+	}
+
+	for _, test := range tests {
+		tracker := Tracker{Info: test.prev, LastSeen: start}
+
+		e, ok := tracker.Observe(start.Add(test.elapsed), test.cur)
+		if ok != test.ok {
+			t.Errorf("%s: expected %v, got %v", test.name, test.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if e.Kind != test.kind || e.Outage != test.outage {
+			t.Errorf("%s: expected %v with outage %v, got %v with outage %v", test.name, test.kind, test.outage,
+				e.Kind, e.Outage)
+		}
+	}
+}
+
+func TestTrackerFirstObservation(t *testing.T) {
+	var tracker Tracker
+
+	if _, ok := tracker.Observe(time.Now(), info(time.Second, time.Minute)); ok {
+		t.Error("expected no event on the first observation")
+	}
+}
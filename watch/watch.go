@@ -0,0 +1,195 @@
+// Package watch notices when LIFX devices rebooted or lost power by following their uptime and downtime, so that
+// automation can react, for example by restoring their state.
+package watch
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yath/controlifx"
+)
+
+const (
+	// DefaultPollInterval is the poll interval of watchers whose options don't specify one.
+	DefaultPollInterval = 30 * time.Second
+
+	// eventBuffer is the number of events buffered before further events are dropped.
+	eventBuffer = 64
+)
+
+// EventKind is the kind of an event.
+type EventKind int
+
+const (
+	// Rebooted is emitted when a device restarted without losing power, or when it isn't known whether it did.
+	Rebooted EventKind = iota
+	// PowerLost is emitted when a device restarted after losing power.
+	PowerLost
+)
+
+func (o EventKind) String() string {
+	switch o {
+	case Rebooted:
+		return "rebooted"
+	case PowerLost:
+		return "power lost"
+	}
+
+	return "unknown"
+}
+
+// Event is a restart of a device.
+type Event struct {
+	Kind   EventKind
+	Device controlifx.Device
+	// Time is the time of the poll that noticed the restart.
+	Time time.Time
+	// Uptime is the uptime of the device at Time.
+	Uptime time.Duration
+	// Outage is the estimated time the device was off. For PowerLost events it's the downtime reported by the
+	// device, which is accurate to about 5 seconds, and otherwise the time its uptime is missing.
+	Outage time.Duration
+	// Info is the info received from the device at Time.
+	Info controlifx.StateInfoLanMessage
+}
+
+// Options configure a watcher.
+type Options struct {
+	// PollInterval is the interval at which the devices are asked for their info.
+	PollInterval time.Duration
+}
+
+// Watcher polls devices with GetInfo and emits an event for each restart.
+type Watcher struct {
+	conn   controlifx.Connection
+	opts   Options
+	events chan Event
+
+	mu       sync.Mutex
+	devices  map[controlifx.MAC]controlifx.Device
+	trackers map[controlifx.MAC]*Tracker
+}
+
+// New returns a watcher without devices.
+func New(conn controlifx.Connection, opts Options) *Watcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	return &Watcher{
+		conn:     conn,
+		opts:     opts,
+		events:   make(chan Event, eventBuffer),
+		devices:  make(map[controlifx.MAC]controlifx.Device),
+		trackers: make(map[controlifx.MAC]*Tracker),
+	}
+}
+
+// Add starts watching the device. Restarts are noticed from the second poll the device responds to on.
+func (o *Watcher) Add(device controlifx.Device) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.devices[device.Mac] = device
+	if _, ok := o.trackers[device.Mac]; !ok {
+		o.trackers[device.Mac] = &Tracker{}
+	}
+}
+
+// Remove stops watching the device.
+func (o *Watcher) Remove(mac controlifx.MAC) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.devices, mac)
+	delete(o.trackers, mac)
+}
+
+// Trackers returns the state of the tracker of each device, by MAC address.
+func (o *Watcher) Trackers() map[controlifx.MAC]Tracker {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	trackers := make(map[controlifx.MAC]Tracker, len(o.trackers))
+	for mac, t := range o.trackers {
+		trackers[mac] = *t
+	}
+
+	return trackers
+}
+
+// Events returns the channel the events are sent on. Events are dropped while the channel is full, so it should be
+// drained continuously.
+func (o *Watcher) Events() <-chan Event {
+	return o.events
+}
+
+// Run polls the devices every PollInterval until the context is done, and returns the context's error.
+func (o *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Errors are transient; devices that didn't respond are polled again next time.
+		o.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll asks all devices for their info once and returns the events of the devices that restarted, which are also
+// sent on the Events channel.
+func (o *Watcher) Poll(ctx context.Context) (events []Event, err error) {
+	o.mu.Lock()
+	devices := make([]controlifx.Device, 0, len(o.devices))
+	for _, d := range o.devices {
+		devices = append(devices, d)
+	}
+	o.mu.Unlock()
+
+	if len(devices) == 0 {
+		return
+	}
+
+	recMsgs, err := o.conn.SendToAndGetContext(ctx, controlifx.GetInfo(), devices,
+		controlifx.TypeFilter(controlifx.StateInfoType))
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for d, recMsg := range recMsgs {
+		t, ok := o.trackers[d.Mac]
+		if !ok {
+			continue
+		}
+
+		if e, ok := t.Observe(now, *recMsg.Payload.(*controlifx.StateInfoLanMessage)); ok {
+			e.Device = d
+			events = append(events, e)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Device.Mac < events[j].Device.Mac
+	})
+
+	for _, e := range events {
+		select {
+		case o.events <- e:
+		default:
+		}
+	}
+
+	return
+}
@@ -0,0 +1,53 @@
+package controlifx
+
+import "math"
+
+// ColorAdjustment reports how AdaptColor changed a color.
+type ColorAdjustment struct {
+	// Whitened is set if the color was replaced by a white because the product can't show colors.
+	Whitened bool
+	// KelvinClamped is set if the color temperature was outside of the product's range.
+	KelvinClamped bool
+}
+
+// Adjusted returns whether the color was changed at all.
+func (o ColorAdjustment) Adjusted() bool {
+	return o.Whitened || o.KelvinClamped
+}
+
+// AdaptColor returns the color closest to c that a product with the features can show. For white-only products, the
+// saturation is dropped and the color temperature moves towards the black body color closest to the hue, the more
+// so the more saturated the color is. The color temperature is then clamped to the product's range.
+func (o ProductFeatures) AdaptColor(c HSBK) (HSBK, ColorAdjustment) {
+	var adj ColorAdjustment
+
+	if !o.Color && c.Saturation > 0 {
+		hr, hg, hb := hsvToRgb(float64(c.Hue)/0x10000*360, 1, 1)
+		hueKelvin := RGBToKelvin(RGB{
+			R: uint8(math.Round(hr * 0xff)),
+			G: uint8(math.Round(hg * 0xff)),
+			B: uint8(math.Round(hb * 0xff)),
+		})
+
+		// Blend in mireds, which are perceptually more uniform than kelvins.
+		s := float64(c.Saturation) / 0xffff
+		kelvin := c.Kelvin
+		if kelvin == 0 {
+			kelvin = DefaultKelvin
+		}
+		c.Kelvin = MiredToKelvin(lerp(KelvinToMired(kelvin), KelvinToMired(hueKelvin), s))
+
+		c.Hue, c.Saturation = 0, 0
+		adj.Whitened = true
+	}
+
+	if o.MinKelvin != 0 && c.Kelvin < o.MinKelvin {
+		c.Kelvin = o.MinKelvin
+		adj.KelvinClamped = true
+	} else if o.MaxKelvin != 0 && c.Kelvin > o.MaxKelvin {
+		c.Kelvin = o.MaxKelvin
+		adj.KelvinClamped = true
+	}
+
+	return c, adj
+}
@@ -0,0 +1,46 @@
+package controlifx
+
+import "testing"
+
+func TestProductFeatures_AdaptColor(t *testing.T) {
+	white800, _ := LookupProduct(White800LowVVendorId, White800LowVProductId)
+	color1000, _ := LookupProduct(Color1000VendorId, Color1000ProductId)
+
+	tests := []struct {
+		features ProductFeatures
+		c        HSBK
+		expected HSBK
+		adj      ColorAdjustment
+	}{
+		{white800.Features, HSBK{0, 0, 0x8000, 3500}, HSBK{0, 0, 0x8000, 3500}, ColorAdjustment{}},
+		{white800.Features, HSBK{0, 0, 0x8000, 9000}, HSBK{0, 0, 0x8000, 6500}, ColorAdjustment{KelvinClamped: true}},
+		{white800.Features, HSBK{0, 0xffff, 0x8000, 3500}, HSBK{0, 0, 0x8000, 2700}, ColorAdjustment{Whitened: true, KelvinClamped: true}},
+		{white800.Features, HSBK{43691, 0xffff, 0xffff, 3500}, HSBK{0, 0, 0xffff, 6500}, ColorAdjustment{Whitened: true, KelvinClamped: true}},
+		{color1000.Features, HSBK{43691, 0xffff, 0xffff, 1000}, HSBK{43691, 0xffff, 0xffff, 2500}, ColorAdjustment{KelvinClamped: true}},
+		{color1000.Features, HSBK{43691, 0xffff, 0xffff, 3500}, HSBK{43691, 0xffff, 0xffff, 3500}, ColorAdjustment{}},
+	}
+
+	for _, test := range tests {
+		c, adj := test.features.AdaptColor(test.c)
+		if test.expected != c || test.adj != adj {
+			t.Errorf("%#v: expected '%#v' '%#v', got '%#v' '%#v'", test.c, test.expected, test.adj, c, adj)
+		}
+	}
+}
+
+func TestProductFeatures_AdaptColor2(t *testing.T) {
+	white800, _ := LookupProduct(White800LowVVendorId, White800LowVProductId)
+
+	// A slightly orange white becomes warmer, but not as warm as a fully saturated orange.
+	orange := HSBKFromHSV(30, 100, 100)
+	orange.Kelvin = 5000
+
+	full, _ := white800.Features.AdaptColor(orange)
+
+	orange.Saturation = 0x2000
+	slight, adj := white800.Features.AdaptColor(orange)
+
+	if !adj.Whitened || slight.Kelvin >= 5000 || slight.Kelvin <= full.Kelvin {
+		t.Errorf("unexpected kelvins '%d' and '%d'", slight.Kelvin, full.Kelvin)
+	}
+}